@@ -22,7 +22,7 @@ func (EchoHandler) Handle(ctx *vex.Context, data []byte) ([]byte, error) {
 }
 
 func main() {
-	server := vex.NewServer("127.0.0.1:9876", EchoHandler{})
+	server := vex.NewServer("127.0.0.1:9876", EchoHandler{}, vex.WithSignals())
 	defer server.Close()
 
 	if err := server.Serve(); err != nil {
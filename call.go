@@ -0,0 +1,129 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vex
+
+import (
+	"context"
+	"fmt"
+)
+
+type codecKey struct{}
+
+type contentTypeKey struct{}
+
+type responseContentTypeKey struct{}
+
+// ContextWithCodec returns a new context carrying the codec which will be used by Call.
+// JSONCodec will be used if no codec is carried.
+func ContextWithCodec(ctx context.Context, codec Codec) context.Context {
+	return context.WithValue(ctx, codecKey{}, codec)
+}
+
+func codecFromContext(ctx context.Context) Codec {
+	codec, ok := ctx.Value(codecKey{}).(Codec)
+	if !ok {
+		return JSONCodec{}
+	}
+
+	return codec
+}
+
+func contextWithContentType(ctx context.Context, contentType ContentType) context.Context {
+	return context.WithValue(ctx, contentTypeKey{}, contentType)
+}
+
+func contentTypeFromContext(ctx context.Context) ContentType {
+	contentType, _ := ctx.Value(contentTypeKey{}).(ContentType)
+	return contentType
+}
+
+// contextWithResponseContentType returns a new context carrying a pointer which will be set to the content type of response.
+func contextWithResponseContentType(ctx context.Context, contentType *ContentType) context.Context {
+	return context.WithValue(ctx, responseContentTypeKey{}, contentType)
+}
+
+func responseContentTypeFromContext(ctx context.Context) *ContentType {
+	contentType, _ := ctx.Value(responseContentTypeKey{}).(*ContentType)
+	return contentType
+}
+
+func newContentTypeError(got ContentType, want ContentType) error {
+	message := fmt.Sprintf("vex: content type %d mismatches codec %d", got, want)
+	return NewError(CodeBadRequest, message)
+}
+
+// Call calls the method with req encoded by the codec in ctx and decodes the response to Resp.
+// Use ContextWithCodec to choose a codec, and JSONCodec will be used by default.
+// The content type of codec is sent to server so mismatches will fail instead of corrupting data.
+func Call[Req any, Resp any](ctx context.Context, client Client, method string, req Req) (resp Resp, err error) {
+	codec := codecFromContext(ctx)
+
+	data, err := codec.Marshal(req)
+	if err != nil {
+		return resp, err
+	}
+
+	var contentType ContentType
+	ctx = ContextWithMethod(ctx, method)
+	ctx = contextWithContentType(ctx, codec.ContentType())
+	ctx = contextWithResponseContentType(ctx, &contentType)
+
+	data, err = client.Send(ctx, data)
+	if err != nil {
+		return resp, err
+	}
+
+	if contentType != codec.ContentType() {
+		return resp, newContentTypeError(contentType, codec.ContentType())
+	}
+
+	err = codec.Unmarshal(data, &resp)
+	return resp, err
+}
+
+// HandleFunc returns a handler which decodes the request to Req, calls fn and encodes the response.
+// The codec is chosen by the content type of request and the response uses the same codec.
+// JSONCodec and GobCodec will be used if no codecs are given.
+// Requests with a content type not supported by codecs will be rejected with a bad request error.
+func HandleFunc[Req any, Resp any](fn func(ctx *Context, req Req) (Resp, error), codecs ...Codec) HandlerFunc {
+	if fn == nil {
+		panic("vex: handle func is nil")
+	}
+
+	if len(codecs) == 0 {
+		codecs = []Codec{JSONCodec{}, GobCodec{}}
+	}
+
+	codecMap := make(map[ContentType]Codec, len(codecs))
+	for _, codec := range codecs {
+		codecMap[codec.ContentType()] = codec
+	}
+
+	return func(ctx *Context, data []byte) ([]byte, error) {
+		codec, ok := codecMap[ctx.contentType]
+		if !ok {
+			message := fmt.Sprintf("vex: content type %d isn't supported", ctx.contentType)
+			return nil, NewError(CodeBadRequest, message)
+		}
+
+		var req Req
+		if err := codec.Unmarshal(data, &req); err != nil {
+			return nil, NewError(CodeBadRequest, err.Error())
+		}
+
+		resp, err := fn(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err = codec.Marshal(resp)
+		if err != nil {
+			return nil, err
+		}
+
+		ctx.responseContentType = codec.ContentType()
+		return data, nil
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vex
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testCallRequest struct {
+	Name string
+}
+
+type testCallResponse struct {
+	Greeting string
+}
+
+// go test -v -cover -run=^TestCall$
+func TestCall(t *testing.T) {
+	greet := func(ctx *Context, req testCallRequest) (testCallResponse, error) {
+		if req.Name == "" {
+			return testCallResponse{}, ErrBadRequest
+		}
+
+		resp := testCallResponse{Greeting: "hello " + req.Name}
+		return resp, nil
+	}
+
+	echo := func(ctx *Context, req []byte) ([]byte, error) {
+		return req, nil
+	}
+
+	router := NewRouter()
+	router.Register("greet", HandleFunc(greet))
+	router.Register("echo", HandleFunc(echo, RawCodec{}))
+	router.RegisterFunc("plain", func(ctx *Context, data []byte) ([]byte, error) {
+		return data, nil
+	})
+
+	svr := NewServer("127.0.0.1:0", router)
+
+	go func() {
+		if err := svr.Serve(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	defer svr.Close()
+
+	time.Sleep(100 * time.Millisecond)
+	address := svr.(*server).listener.Addr().String()
+
+	client, err := NewClient(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	ctx := context.Background()
+	req := testCallRequest{Name: "vex"}
+	want := testCallResponse{Greeting: "hello vex"}
+
+	for _, codec := range []Codec{JSONCodec{}, GobCodec{}} {
+		resp, err := Call[testCallRequest, testCallResponse](ContextWithCodec(ctx, codec), client, "greet", req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp != want {
+			t.Fatalf("got %+v != want %+v", resp, want)
+		}
+	}
+
+	// JSON codec is the default one.
+	if resp, err := Call[testCallRequest, testCallResponse](ctx, client, "greet", req); err != nil || resp != want {
+		t.Fatalf("got (%+v, %+v) != want (%+v, nil)", resp, err, want)
+	}
+
+	if _, err = Call[testCallRequest, testCallResponse](ctx, client, "greet", testCallRequest{}); !errors.Is(err, ErrBadRequest) {
+		t.Fatalf("got %+v != want %+v", err, ErrBadRequest)
+	}
+
+	raw, err := Call[[]byte, []byte](ContextWithCodec(ctx, RawCodec{}), client, "echo", []byte("raw"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(raw) != "raw" {
+		t.Fatalf("got %s != want %s", raw, "raw")
+	}
+
+	// The server doesn't support the codec of client.
+	_, err = Call[[]byte, []byte](ContextWithCodec(ctx, RawCodec{}), client, "greet", []byte("raw"))
+	if !errors.Is(err, ErrBadRequest) || !strings.Contains(err.Error(), "isn't supported") {
+		t.Fatalf("got %+v is wrong", err)
+	}
+
+	// The plain handler doesn't encode the response with codec.
+	_, err = Call[testCallRequest, testCallResponse](ctx, client, "plain", req)
+	if !errors.Is(err, ErrBadRequest) || !strings.Contains(err.Error(), "mismatches") {
+		t.Fatalf("got %+v is wrong", err)
+	}
+
+	// The plain send doesn't have a content type.
+	_, err = client.Send(ContextWithMethod(ctx, "greet"), []byte(`{"Name":"vex"}`))
+	if !errors.Is(err, ErrBadRequest) {
+		t.Fatalf("got %+v != want %+v", err, ErrBadRequest)
+	}
+}
+
+// go test -v -cover -run=^TestHandleFunc$
+func TestHandleFunc(t *testing.T) {
+	handler := HandleFunc(func(ctx *Context, req int) (int, error) {
+		return req * 2, nil
+	})
+
+	ctx := &Context{contentType: ContentTypeJSON}
+
+	data, err := handler.Handle(ctx, []byte("21"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "42" {
+		t.Fatalf("got %s != want 42", data)
+	}
+
+	if ctx.ContentType() != ContentTypeJSON || ctx.responseContentType != ContentTypeJSON {
+		t.Fatalf("got (%d, %d) is wrong", ctx.ContentType(), ctx.responseContentType)
+	}
+
+	if _, err = handler.Handle(ctx, []byte("wrong")); !errors.Is(err, ErrBadRequest) {
+		t.Fatalf("got %+v != want %+v", err, ErrBadRequest)
+	}
+
+	t.Run("nil func", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Fatal("nil func returns a nil recover")
+			}
+		}()
+
+		HandleFunc[int, int](nil)
+	})
+}
@@ -7,20 +7,34 @@ package vex
 import (
 	"bufio"
 	"context"
+	"crypto/tls"
 	"errors"
+	"io"
+	"iter"
+	"maps"
 	"net"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	histograms "github.com/FishGoddess/vex/internal/histogram"
 	packets "github.com/FishGoddess/vex/internal/packet"
 )
 
 var (
-	errClientClosed = errors.New("vex: client is closed")
+	errClientClosed     = errors.New("vex: client is closed")
+	errConnectionLost   = errors.New("vex: connection is lost")
+	errHeartbeatTimeout = errors.New("vex: heartbeat timeout")
+	errServerGoingAway  = NewError(CodeUnavailable, "vex: server is going away")
 )
 
 // Client is the interface of vex client.
 type Client interface {
 	Send(ctx context.Context, data []byte) ([]byte, error)
+	Notify(ctx context.Context, data []byte) error
+	SendStream(ctx context.Context, data []byte) iter.Seq2[[]byte, error]
+	OpenStream(ctx context.Context) (Stream, error)
+	Stats() ClientStats
 	Close() error
 }
 
@@ -28,25 +42,80 @@ type client struct {
 	conf *config
 
 	ctx    context.Context
-	cancel context.CancelFunc
+	cancel context.CancelCauseFunc
 
 	conn       net.Conn
+	writer     io.Writer
+	lastRead   atomic.Int64
 	inflight   map[uint64]chan packets.Packet
+	streams    map[uint64]*clientStream
 	inflightID uint64
+	goingAway  bool
+	sendFunc   SendFunc
+
+	sent         atomic.Uint64
+	errors       atomic.Uint64
+	timeouts     atomic.Uint64
+	bytesRead    atomic.Uint64
+	bytesWritten atomic.Uint64
+	latency      *histograms.Histogram[time.Duration]
 
 	lock sync.Mutex
 }
 
 // NewClient creates a client with address.
+// The client will reconnect automatically if WithReconnect is used.
 func NewClient(address string, opts ...Option) (Client, error) {
 	conf := newConfig().apply(opts...)
 
-	conn, err := net.DialTimeout("tcp", address, conf.dialTimeout)
+	if conf.reconnect {
+		return newReconnectClient(address, conf)
+	}
+
+	return newClient(address, conf)
+}
+
+func dial(address string, conf *config) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), conf.dialTimeout)
+	defer cancel()
+
+	conn, err := conf.transport.Dial(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	if conf.tlsConfig == nil {
+		return conn, nil
+	}
+
+	// Use the host of address as the server name like tls.Dial if it's not set.
+	tlsConfig := conf.tlsConfig
+	if tlsConfig.ServerName == "" && !tlsConfig.InsecureSkipVerify {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			host = address
+		}
+
+		tlsConfig = tlsConfig.Clone()
+		tlsConfig.ServerName = host
+	}
+
+	tlsConn := tls.Client(conn, tlsConfig)
+	if err = tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return tlsConn, nil
+}
+
+func newClient(address string, conf *config) (*client, error) {
+	conn, err := dial(address, conf)
 	if err != nil {
 		return nil, err
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancelCause(context.Background())
 	inflight := make(map[uint64]chan packets.Packet, 1024)
 
 	client := new(client)
@@ -54,9 +123,19 @@ func NewClient(address string, opts ...Option) (Client, error) {
 	client.ctx = ctx
 	client.cancel = cancel
 	client.conn = conn
+	client.writer = countWriter{writer: conn, count: client.countBytesWritten}
 	client.inflight = inflight
+	client.streams = make(map[uint64]*clientStream, 16)
+	client.latency = newLatencyHistogram()
+	client.sendFunc = chainClientInterceptors(client.send, conf.clientInterceptors)
+	client.lastRead.Store(time.Now().UnixNano())
 
 	go client.inflightLoop()
+
+	if conf.heartbeatInterval > 0 {
+		go client.heartbeatLoop()
+	}
+
 	return client, nil
 }
 
@@ -67,42 +146,218 @@ func (c *client) inflightPacket(packet packets.Packet) error {
 	default:
 		c.lock.Lock()
 		ch := c.inflight[packet.ID()]
+		cs := c.streams[packet.ID()]
 		c.lock.Unlock()
 
 		if ch != nil {
 			ch <- packet
 		}
 
+		if cs != nil {
+			cs.dispatch(packet)
+		}
+
 		return nil
 	}
 }
 
 func (c *client) inflightLoop() {
-	reader := bufio.NewReader(c.conn)
+	reader := bufio.NewReader(countReader{reader: c.conn, count: c.countBytesRead})
 	for {
 		packet, err := packets.ReadPacket(reader)
 		if err != nil {
-			c.Close()
+			c.closeWithCause(errConnectionLost)
 			return
 		}
 
+		c.lastRead.Store(time.Now().UnixNano())
+
+		if packet.IsPing() {
+			c.pong(packet)
+			continue
+		}
+
+		if packet.IsPong() {
+			continue
+		}
+
+		if packet.IsGoAway() {
+			c.goAway(packet.ID())
+			continue
+		}
+
+		// The server sends an error packet with zero id before closing the connection if it rejects the connection.
+		if packet.ID() == 0 {
+			if _, err = packetData(&packet); err != nil {
+				c.closeWithCause(err)
+				return
+			}
+		}
+
 		if err = c.inflightPacket(packet); err != nil {
 			return
 		}
 	}
 }
 
+// goAway stops sending new requests and fails the inflight requests after lastID which won't be processed.
+// The client will be closed after all remaining inflight requests are done.
+func (c *client) goAway(lastID uint64) {
+	logger := c.conf.logger
+	logger.Info("server is going away", "address", c.conn.RemoteAddr(), "last_id", lastID)
+
+	c.lock.Lock()
+	c.goingAway = true
+
+	var rejected []uint64
+	for id, ch := range c.inflight {
+		if id > lastID {
+			response := packets.New(id)
+			setPacketError(&response, errServerGoingAway)
+
+			// The channel may be full of the response received already, so don't block on it.
+			select {
+			case ch <- response:
+			default:
+			}
+
+			rejected = append(rejected, id)
+		}
+	}
+
+	for _, id := range rejected {
+		delete(c.inflight, id)
+	}
+
+	for id, cs := range c.streams {
+		if id > lastID {
+			cs.fail(errServerGoingAway)
+			delete(c.streams, id)
+		}
+	}
+
+	drained := len(c.inflight) == 0 && len(c.streams) == 0
+	c.lock.Unlock()
+
+	if drained {
+		c.closeWithCause(errServerGoingAway)
+	}
+}
+
+func (c *client) pong(ping packets.Packet) {
+	pong := packets.New(ping.ID())
+	pong.SetPong()
+
+	if err := packets.WritePacket(c.writer, pong); err != nil {
+		logger := c.conf.logger
+		logger.Debug("write pong packet failed", "err", err)
+	}
+}
+
+func (c *client) heartbeatLoop() {
+	logger := c.conf.logger
+
+	ticker := time.NewTicker(c.conf.heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			lastRead := time.Unix(0, c.lastRead.Load())
+			if time.Since(lastRead) > c.conf.heartbeatTimeout {
+				logger.Error("heartbeat timeout", "address", c.conn.RemoteAddr(), "last_read", lastRead)
+
+				c.closeWithCause(errHeartbeatTimeout)
+				return
+			}
+
+			ping := packets.New(0)
+			ping.SetPing()
+
+			if err := packets.WritePacket(c.writer, ping); err != nil {
+				logger.Debug("write ping packet failed", "err", err)
+			}
+		case <-c.ctx.Done():
+			return
+		}
+	}
+}
+
 func (c *client) nextInflightID() uint64 {
 	c.inflightID++
 	return c.inflightID
 }
 
-func (c *client) handleData(data []byte) (packet packets.Packet, packetCh chan packets.Packet, done func(), err error) {
+// requestTimeout returns the timeout of request which is zero if the context has no deadline.
+func requestTimeout(ctx context.Context) (time.Duration, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, nil
+	}
+
+	timeout := time.Until(deadline)
+	if timeout <= 0 {
+		return 0, context.DeadlineExceeded
+	}
+
+	return timeout, nil
+}
+
+func newRequestPacket(ctx context.Context, id uint64, timeout time.Duration, data []byte) packets.Packet {
+	packet := packets.New(id)
+	packet.SetMethod(MethodFromContext(ctx))
+	packet.SetMetadata(MetadataFromContext(ctx))
+	packet.SetContentType(uint8(contentTypeFromContext(ctx)))
+	packet.SetTimeout(timeout)
+	packet.SetData(data)
+	return packet
+}
+
+// lockAvailable locks the client if it can send new requests, otherwise returns an error without locking.
+func (c *client) lockAvailable() error {
 	c.lock.Lock()
 	if c.inflight == nil {
 		c.lock.Unlock()
 
-		return packet, nil, nil, errClientClosed
+		// Return the cause so we can know why the client is closed, like rejected by server.
+		if c.ctx != nil {
+			return context.Cause(c.ctx)
+		}
+
+		return errClientClosed
+	}
+
+	if c.goingAway {
+		c.lock.Unlock()
+
+		return errServerGoingAway
+	}
+
+	return nil
+}
+
+// removeInflight removes the inflight request or stream with id.
+// The client will be closed if it's going away and all inflight requests are done.
+func (c *client) removeInflight(id uint64) {
+	c.lock.Lock()
+	delete(c.inflight, id)
+	delete(c.streams, id)
+	drained := c.goingAway && c.inflight != nil && len(c.inflight) == 0 && len(c.streams) == 0
+	c.lock.Unlock()
+
+	if drained {
+		c.closeWithCause(errServerGoingAway)
+	}
+}
+
+func (c *client) handleData(ctx context.Context, data []byte) (packet packets.Packet, packetCh chan packets.Packet, done func(), err error) {
+	timeout, err := requestTimeout(ctx)
+	if err != nil {
+		return packet, nil, nil, err
+	}
+
+	if err = c.lockAvailable(); err != nil {
+		return packet, nil, nil, err
 	}
 
 	inflightID := c.nextInflightID()
@@ -111,47 +366,150 @@ func (c *client) handleData(data []byte) (packet packets.Packet, packetCh chan p
 	c.lock.Unlock()
 
 	done = func() {
-		c.lock.Lock()
-		delete(c.inflight, inflightID)
-		c.lock.Unlock()
+		c.removeInflight(inflightID)
 	}
 
-	packet = packets.New(inflightID)
-	packet.SetData(data)
+	packet = newRequestPacket(ctx, inflightID, timeout, data)
 	return packet, packetCh, done, nil
 }
 
-func (c *client) waitData(ctx context.Context, packetCh chan packets.Packet) ([]byte, error) {
+// cancelRequest tells server to cancel the request with id because nobody waits for its response.
+func (c *client) cancelRequest(id uint64) {
+	cancelPacket := packets.New(id)
+	cancelPacket.SetCancel()
+
+	if err := packets.WritePacket(c.writer, cancelPacket); err != nil {
+		logger := c.conf.logger
+		logger.Debug("write cancel packet failed", "err", err, "id", id)
+	}
+}
+
+func (c *client) waitData(ctx context.Context, packet packets.Packet, packetCh chan packets.Packet) ([]byte, error) {
 	select {
 	case packet := <-packetCh:
-		return packet.Data()
+		if metadata := responseMetadataFromContext(ctx); metadata != nil {
+			maps.Copy(metadata, packet.Metadata())
+		}
+
+		if contentType := responseContentTypeFromContext(ctx); contentType != nil {
+			*contentType = ContentType(packet.ContentType())
+		}
+
+		return packetData(&packet)
 	case <-ctx.Done():
+		c.cancelRequest(packet.ID())
 		return nil, ctx.Err()
 	case <-c.ctx.Done():
-		return nil, errClientClosed
+		return nil, context.Cause(c.ctx)
 	}
 }
 
-// Send sends data and gets a new data.
-// Returns an error if failed.
-func (c *client) Send(ctx context.Context, data []byte) ([]byte, error) {
-	packet, packetCh, done, err := c.handleData(data)
+func (c *client) countBytesRead(n int) {
+	c.bytesRead.Add(uint64(n))
+}
+
+func (c *client) countBytesWritten(n int) {
+	c.bytesWritten.Add(uint64(n))
+}
+
+// record records the latency and error of a sent request.
+func (c *client) record(latency time.Duration, err error) {
+	c.latency.Observe(latency)
+
+	if err == nil {
+		return
+	}
+
+	c.errors.Add(1)
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrTimeout) {
+		c.timeouts.Add(1)
+	}
+}
+
+func (c *client) send(ctx context.Context, data []byte) (result []byte, err error) {
+	packet, packetCh, done, err := c.handleData(ctx, data)
 	if err != nil {
 		return nil, err
 	}
 
 	defer done()
 
-	err = packets.WritePacket(c.conn, packet)
+	c.sent.Add(1)
+
+	begin := time.Now()
+	defer func() {
+		c.record(time.Since(begin), err)
+	}()
+
+	err = packets.WritePacket(c.writer, packet)
 	if err != nil {
 		return nil, err
 	}
 
-	return c.waitData(ctx, packetCh)
+	return c.waitData(ctx, packet, packetCh)
 }
 
-// Close closes the client and returns an error if failed.
-func (c *client) Close() error {
+// Send sends data and gets a new data.
+// Returns an error if failed.
+func (c *client) Send(ctx context.Context, data []byte) ([]byte, error) {
+	if c.sendFunc == nil {
+		return c.send(ctx, data)
+	}
+
+	return c.sendFunc(ctx, data)
+}
+
+// Notify sends data without waiting for a response, so it returns after writing the data.
+// The server handles the data as usual but never responds it, even if the handler returns an error.
+// Client interceptors won't be applied to notifications.
+func (c *client) Notify(ctx context.Context, data []byte) error {
+	timeout, err := requestTimeout(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err = c.lockAvailable(); err != nil {
+		return err
+	}
+
+	inflightID := c.nextInflightID()
+	c.lock.Unlock()
+
+	// Notifications don't have responses, so only the errors will be recorded without latency.
+	c.sent.Add(1)
+
+	packet := newRequestPacket(ctx, inflightID, timeout, data)
+	packet.SetOneWay()
+
+	if err = packets.WritePacket(c.writer, packet); err != nil {
+		c.errors.Add(1)
+		return err
+	}
+
+	return nil
+}
+
+// Stats returns the statistics of client.
+func (c *client) Stats() ClientStats {
+	c.lock.Lock()
+	inflight := len(c.inflight) + len(c.streams)
+	c.lock.Unlock()
+
+	stats := ClientStats{
+		Inflight:     uint64(inflight),
+		Sent:         c.sent.Load(),
+		Errors:       c.errors.Load(),
+		Timeouts:     c.timeouts.Load(),
+		BytesRead:    c.bytesRead.Load(),
+		BytesWritten: c.bytesWritten.Load(),
+		Latency:      latencySnapshot(c.latency),
+	}
+
+	return stats
+}
+
+func (c *client) closeWithCause(cause error) error {
 	c.lock.Lock()
 	if err := c.conn.Close(); err != nil {
 		c.lock.Unlock()
@@ -159,9 +517,28 @@ func (c *client) Close() error {
 		return err
 	}
 
-	c.cancel()
+	c.cancel(cause)
 	c.inflight = nil
+	c.streams = nil
 	c.inflightID = 0
 	c.lock.Unlock()
 	return nil
 }
+
+// available returns if the client can send new requests.
+func (c *client) available() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.inflight != nil && !c.goingAway
+}
+
+// closed returns if the client is closed, like closing itself after draining the requests of going away.
+func (c *client) closed() bool {
+	return c.ctx.Err() != nil
+}
+
+// Close closes the client and returns an error if failed.
+func (c *client) Close() error {
+	return c.closeWithCause(errClientClosed)
+}
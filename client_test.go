@@ -268,3 +268,353 @@ func TestClientClose(t *testing.T) {
 		t.Fatalf("got %+v != want %+v", err, errClientClosed)
 	}
 }
+
+// go test -v -cover -run=^TestClientHeartbeat$
+func TestClientHeartbeat(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		// Never respond anything so the client will find it dead.
+		defer conn.Close()
+		time.Sleep(time.Second)
+	}()
+
+	client, err := NewClient(listener.Addr().String(), WithHeartbeat(20*time.Millisecond, 100*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	begin := time.Now()
+
+	_, err = client.Send(context.Background(), nil)
+	if err != errHeartbeatTimeout {
+		t.Fatalf("got %+v != want %+v", err, errHeartbeatTimeout)
+	}
+
+	if cost := time.Since(begin); cost > 500*time.Millisecond {
+		t.Fatalf("heartbeat timeout costs %s", cost)
+	}
+}
+
+// go test -v -cover -run=^TestClientGoAway$
+func TestClientGoAway(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		var requests []packets.Packet
+		for range 2 {
+			packet, err := packets.ReadPacket(conn)
+			if err != nil {
+				return
+			}
+
+			requests = append(requests, packet)
+		}
+
+		// Only the first request will be processed.
+		goAway := packets.New(requests[0].ID())
+		goAway.SetGoAway()
+		packets.WritePacket(conn, goAway)
+
+		time.Sleep(50 * time.Millisecond)
+
+		data, _ := requests[0].Data()
+		response := packets.New(requests[0].ID())
+		response.SetData(data)
+		packets.WritePacket(conn, response)
+
+		time.Sleep(time.Second)
+	}()
+
+	conf := newConfig()
+
+	client, err := newClient(listener.Addr().String(), conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	first := make(chan error, 1)
+	go func() {
+		_, err := client.Send(context.Background(), []byte("first"))
+		first <- err
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+
+	_, err = client.Send(context.Background(), []byte("second"))
+	if !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("got %+v != want %+v", err, ErrUnavailable)
+	}
+
+	if client.available() {
+		t.Fatal("client going away is available")
+	}
+
+	if _, err = client.Send(context.Background(), []byte("third")); err != errServerGoingAway {
+		t.Fatalf("got %+v != want %+v", err, errServerGoingAway)
+	}
+
+	if err = <-first; err != nil {
+		t.Fatal(err)
+	}
+
+	if cause := context.Cause(client.ctx); cause != errServerGoingAway {
+		t.Fatalf("got %+v != want %+v", cause, errServerGoingAway)
+	}
+}
+
+// go test -v -cover -run=^TestClientGoAwayResponded$
+func TestClientGoAwayResponded(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+		time.Sleep(time.Second)
+	}()
+
+	conf := newConfig()
+
+	client, err := newClient(listener.Addr().String(), conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	// The response is received but not consumed yet.
+	ch := make(chan packets.Packet, 1)
+	ch <- packets.New(1)
+
+	client.lock.Lock()
+	client.inflight[1] = ch
+	client.lock.Unlock()
+
+	goAway := make(chan struct{})
+	go func() {
+		client.goAway(0)
+		close(goAway)
+	}()
+
+	select {
+	case <-goAway:
+	case <-time.After(time.Second):
+		t.Fatal("go away is blocked")
+	}
+
+	packet := <-ch
+	if _, err := packet.Data(); err != nil {
+		t.Fatalf("got %+v != want nil", err)
+	}
+}
+
+// go test -v -cover -run=^TestClientStats$
+func TestClientStats(t *testing.T) {
+	handler := HandlerFunc(func(ctx *Context, data []byte) ([]byte, error) {
+		switch string(data) {
+		case "slow":
+			time.Sleep(200 * time.Millisecond)
+		case "error":
+			return nil, ErrInternal
+		}
+
+		return data, nil
+	})
+
+	svr := NewServer("127.0.0.1:0", handler)
+
+	go func() {
+		if err := svr.Serve(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	defer svr.Close()
+
+	time.Sleep(100 * time.Millisecond)
+	address := svr.(*server).listener.Addr().String()
+
+	client, err := NewClient(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	if _, err = client.Send(context.Background(), []byte("ok")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = client.Send(context.Background(), []byte("error")); err == nil {
+		t.Fatal("send error returns a nil error")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err = client.Send(ctx, []byte("slow")); err != context.DeadlineExceeded {
+		t.Fatalf("got %+v != want %+v", err, context.DeadlineExceeded)
+	}
+
+	sent := make(chan struct{})
+	go func() {
+		client.Send(context.Background(), []byte("slow"))
+		close(sent)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+
+	stats := client.Stats()
+	if stats.Inflight != 1 || stats.Sent != 4 || stats.Errors != 2 || stats.Timeouts != 1 {
+		t.Fatalf("got %+v is wrong", stats)
+	}
+
+	if stats.BytesRead == 0 || stats.BytesWritten == 0 {
+		t.Fatalf("got bytes (%d, %d) is wrong", stats.BytesRead, stats.BytesWritten)
+	}
+
+	if stats.Latency.Count != 3 {
+		t.Fatalf("got %d != want 3", stats.Latency.Count)
+	}
+
+	<-sent
+
+	if stats = client.Stats(); stats.Inflight != 0 || stats.Latency.Count != 4 {
+		t.Fatalf("got %+v is wrong", stats)
+	}
+}
+
+// go test -v -cover -run=^TestClientNotify$
+func TestClientNotify(t *testing.T) {
+	notified := make(chan string, 16)
+
+	handler := HandlerFunc(func(ctx *Context, data []byte) ([]byte, error) {
+		notified <- string(data)
+
+		// The error won't be responded to client since it's a notification.
+		return nil, ErrInternal
+	})
+
+	svr := NewServer("127.0.0.1:0", handler)
+
+	go func() {
+		if err := svr.Serve(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	defer svr.Close()
+
+	time.Sleep(100 * time.Millisecond)
+	address := svr.(*server).listener.Addr().String()
+
+	cli, err := NewClient(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer cli.Close()
+
+	reconnectClient, err := NewClient(address, WithReconnect(10*time.Millisecond, 100*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer reconnectClient.Close()
+
+	pool := NewPool(1, func(ctx context.Context) (Client, error) {
+		return NewClient(address)
+	})
+
+	defer pool.Close()
+
+	poolClient, err := pool.Get(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer poolClient.Close()
+
+	clients := map[string]Client{
+		"client":    cli,
+		"reconnect": reconnectClient,
+		"pool":      poolClient,
+	}
+
+	for name, client := range clients {
+		t.Run(name, func(t *testing.T) {
+			if err := client.Notify(context.Background(), []byte(name)); err != nil {
+				t.Fatal(err)
+			}
+
+			select {
+			case got := <-notified:
+				if got != name {
+					t.Fatalf("got %s != want %s", got, name)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("notification isn't handled")
+			}
+		})
+	}
+
+	// Wait a moment so the response will be read if the server sends it.
+	time.Sleep(50 * time.Millisecond)
+
+	stats := cli.Stats()
+	if stats.Inflight != 0 || stats.Sent != 1 || stats.Errors != 0 || stats.Latency.Count != 0 {
+		t.Fatalf("got %+v is wrong", stats)
+	}
+
+	if stats.BytesRead != 0 || stats.BytesWritten == 0 {
+		t.Fatalf("got bytes (%d, %d) is wrong", stats.BytesRead, stats.BytesWritten)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+
+	if err = cli.Notify(ctx, nil); err != context.DeadlineExceeded {
+		t.Fatalf("got %+v != want %+v", err, context.DeadlineExceeded)
+	}
+
+	if err = cli.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = cli.Notify(context.Background(), nil); err != errClientClosed {
+		t.Fatalf("got %+v != want %+v", err, errClientClosed)
+	}
+}
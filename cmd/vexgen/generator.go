@@ -0,0 +1,66 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"go/format"
+	"text/template"
+)
+
+const vexPath = "github.com/FishGoddess/vex"
+
+var codeTemplate = template.Must(template.New("vexgen").Parse(`// Code generated by vexgen. DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+	"context"
+{{- range .StdImports }}
+	{{ . }}
+{{- end }}
+
+	"github.com/FishGoddess/vex"
+{{- range .Imports }}
+	{{ . }}
+{{- end }}
+)
+{{ range $service := .Services }}
+// {{ .Interface }}Client is the client of {{ .Name }} service.
+type {{ .Interface }}Client struct {
+	client vex.Client
+}
+
+var _ {{ .Interface }} = (*{{ .Interface }}Client)(nil)
+
+// New{{ .Interface }}Client returns a client of {{ .Name }} service.
+func New{{ .Interface }}Client(client vex.Client) *{{ .Interface }}Client {
+	return &{{ .Interface }}Client{client: client}
+}
+{{ range .Methods }}
+// {{ .Name }} calls {{ $service.Name }}.{{ .Name }} and returns an error if failed.
+func (c *{{ $service.Interface }}Client) {{ .Name }}(ctx context.Context, req {{ .Request }}) ({{ .Response }}, error) {
+	return vex.Call[{{ .Request }}, {{ .Response }}](ctx, c.client, "{{ $service.Name }}.{{ .Name }}", req)
+}
+{{ end }}
+// Register{{ .Interface }} registers the methods of {{ .Name }} service to router.
+func Register{{ .Interface }}(router *vex.Router, service {{ .Interface }}, codecs ...vex.Codec) {
+{{- range .Methods }}
+	router.Register("{{ $service.Name }}.{{ .Name }}", vex.HandleFunc(func(ctx *vex.Context, req {{ .Request }}) ({{ .Response }}, error) {
+		return service.{{ .Name }}(ctx, req)
+	}, codecs...))
+{{- end }}
+}
+{{ end }}`))
+
+// generate generates the code of services in file.
+func generate(f *file) ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := codeTemplate.Execute(&buffer, f); err != nil {
+		return nil, err
+	}
+
+	return format.Source(buffer.Bytes())
+}
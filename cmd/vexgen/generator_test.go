@@ -0,0 +1,166 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// go test -v -cover -run=^TestGenerate$
+func TestGenerate(t *testing.T) {
+	inputs, err := filepath.Glob("testdata/*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(inputs) == 0 {
+		t.Fatal("no inputs in testdata")
+	}
+
+	for _, input := range inputs {
+		t.Run(filepath.Base(input), func(t *testing.T) {
+			src, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			f, err := parseFile(input, src)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := generate(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			golden := strings.TrimSuffix(input, ".go") + ".golden"
+			if *update {
+				if err = os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(got) != string(want) {
+				t.Fatalf("got %s != want %s", got, want)
+			}
+		})
+	}
+}
+
+// go test -v -cover -run=^TestParseFileError$
+func TestParseFileError(t *testing.T) {
+	testCases := map[string]string{
+		"no context": `package test
+//vex:service
+type Service interface {
+	Call(req string) (string, error)
+}`,
+		"no error": `package test
+import "context"
+//vex:service
+type Service interface {
+	Call(ctx context.Context, req string) (string, string)
+}`,
+		"two requests": `package test
+import "context"
+//vex:service
+type Service interface {
+	Call(ctx context.Context, a string, b string) (string, error)
+}`,
+		"embedded": `package test
+import "io"
+//vex:service
+type Service interface {
+	io.Closer
+}`,
+		"not interface": `package test
+//vex:service
+type Service struct{}`,
+		"syntax": `package test
+type Service interface {`,
+	}
+
+	for name, src := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := parseFile("test.go", []byte(src)); err == nil {
+				t.Fatal("parse wrong file returns a nil error")
+			}
+		})
+	}
+}
+
+// go test -v -cover -run=^TestServiceName$
+func TestServiceName(t *testing.T) {
+	src := `package test
+import "context"
+//vex:servicex
+type NotService interface{}
+// Named is a service.
+//vex:service named
+type Named interface {
+	Call(ctx context.Context, req string) (string, error)
+}`
+
+	f, err := parseFile("test.go", []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(f.Services) != 1 {
+		t.Fatalf("got %d != want 1", len(f.Services))
+	}
+
+	if f.Services[0].Interface != "Named" || f.Services[0].Name != "named" {
+		t.Fatalf("got %+v is wrong", f.Services[0])
+	}
+
+	if len(f.Imports) != 0 {
+		t.Fatalf("got %+v is wrong", f.Imports)
+	}
+}
+
+// go test -v -cover -run=^TestRun$
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "greeter.go")
+
+	src, err := os.ReadFile("testdata/greeter.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = os.WriteFile(input, src, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = run(input, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = os.Stat(filepath.Join(dir, "greeter_vex.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	empty := filepath.Join(dir, "empty.go")
+	if err = os.WriteFile(empty, []byte("package test\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = run(empty, ""); err == nil {
+		t.Fatal("run file without services returns a nil error")
+	}
+}
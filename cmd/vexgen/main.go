@@ -0,0 +1,69 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var (
+	errNoServices = errors.New("vexgen: no services found")
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: vexgen [-output file] file.go")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Generates typed clients and registration functions for interfaces annotated with //vex:service.")
+	fmt.Fprintln(os.Stderr, "Methods of services should be like Method(ctx context.Context, req Req) (Resp, error).")
+	fmt.Fprintln(os.Stderr, "")
+	flag.PrintDefaults()
+}
+
+func run(input string, output string) error {
+	src, err := os.ReadFile(input)
+	if err != nil {
+		return err
+	}
+
+	f, err := parseFile(input, src)
+	if err != nil {
+		return err
+	}
+
+	if len(f.Services) == 0 {
+		return fmt.Errorf("%w in %s", errNoServices, input)
+	}
+
+	code, err := generate(f)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		output = strings.TrimSuffix(input, ".go") + "_vex.go"
+	}
+
+	return os.WriteFile(output, code, 0644)
+}
+
+func main() {
+	output := flag.String("output", "", "the output file, default is <file>_vex.go")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(flag.Arg(0), *output); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
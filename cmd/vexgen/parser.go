@@ -0,0 +1,247 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// serviceAnnotation marks an interface as a vex service, and it can be followed by the name of service.
+const serviceAnnotation = "//vex:service"
+
+type method struct {
+	Name     string
+	Request  string
+	Response string
+}
+
+type service struct {
+	Interface string
+	Name      string
+	Methods   []method
+}
+
+type file struct {
+	Package    string
+	StdImports []string
+	Imports    []string
+	Services   []service
+}
+
+func exprString(fset *token.FileSet, expr ast.Expr) (string, error) {
+	var buffer bytes.Buffer
+	if err := format.Node(&buffer, fset, expr); err != nil {
+		return "", err
+	}
+
+	return buffer.String(), nil
+}
+
+// usedPackages returns the names of packages used by expr like time in time.Duration.
+func usedPackages(expr ast.Expr) []string {
+	var packages []string
+	ast.Inspect(expr, func(node ast.Node) bool {
+		if selector, ok := node.(*ast.SelectorExpr); ok {
+			if ident, ok := selector.X.(*ast.Ident); ok {
+				packages = append(packages, ident.Name)
+			}
+		}
+
+		return true
+	})
+
+	return packages
+}
+
+func isContext(fset *token.FileSet, expr ast.Expr) bool {
+	str, err := exprString(fset, expr)
+	return err == nil && str == "context.Context"
+}
+
+func isError(expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == "error"
+}
+
+func fieldCount(fields *ast.FieldList) int {
+	if fields == nil {
+		return 0
+	}
+
+	count := 0
+	for _, field := range fields.List {
+		count += max(len(field.Names), 1)
+	}
+
+	return count
+}
+
+// parseMethod parses the method which should be like Method(ctx context.Context, req Req) (Resp, error).
+func parseMethod(fset *token.FileSet, field *ast.Field) (method, []string, error) {
+	position := fset.Position(field.Pos())
+
+	fn, ok := field.Type.(*ast.FuncType)
+	if !ok || len(field.Names) != 1 {
+		return method{}, nil, fmt.Errorf("%s: embedded interfaces aren't supported", position)
+	}
+
+	name := field.Names[0].Name
+	params := fn.Params.List
+	results := fn.Results
+
+	if fieldCount(fn.Params) != 2 || fieldCount(results) != 2 || fn.TypeParams != nil {
+		return method{}, nil, fmt.Errorf("%s: method %s should be like %s(ctx context.Context, req Req) (Resp, error)", position, name, name)
+	}
+
+	ctxType, reqType := params[0].Type, params[len(params)-1].Type
+	respType, errType := results.List[0].Type, results.List[len(results.List)-1].Type
+
+	if !isContext(fset, ctxType) || !isError(errType) {
+		return method{}, nil, fmt.Errorf("%s: method %s should be like %s(ctx context.Context, req Req) (Resp, error)", position, name, name)
+	}
+
+	request, err := exprString(fset, reqType)
+	if err != nil {
+		return method{}, nil, err
+	}
+
+	response, err := exprString(fset, respType)
+	if err != nil {
+		return method{}, nil, err
+	}
+
+	packages := append(usedPackages(reqType), usedPackages(respType)...)
+	return method{Name: name, Request: request, Response: response}, packages, nil
+}
+
+// serviceName returns the name of service if the doc has a service annotation.
+func serviceName(doc *ast.CommentGroup, typeName string) (string, bool) {
+	if doc == nil {
+		return "", false
+	}
+
+	for _, comment := range doc.List {
+		annotation, ok := strings.CutPrefix(comment.Text, serviceAnnotation)
+		if !ok {
+			continue
+		}
+
+		if annotation != "" && annotation[0] != ' ' {
+			continue
+		}
+
+		if name := strings.TrimSpace(annotation); name != "" {
+			return name, true
+		}
+
+		return typeName, true
+	}
+
+	return "", false
+}
+
+func parseService(fset *token.FileSet, genDecl *ast.GenDecl, spec *ast.TypeSpec) (service, []string, bool, error) {
+	doc := spec.Doc
+	if doc == nil && len(genDecl.Specs) == 1 {
+		doc = genDecl.Doc
+	}
+
+	name, ok := serviceName(doc, spec.Name.Name)
+	if !ok {
+		return service{}, nil, false, nil
+	}
+
+	iface, ok := spec.Type.(*ast.InterfaceType)
+	if !ok || spec.TypeParams != nil {
+		position := fset.Position(spec.Pos())
+		return service{}, nil, false, fmt.Errorf("%s: service %s should be a non-generic interface", position, spec.Name.Name)
+	}
+
+	svc := service{Interface: spec.Name.Name, Name: name}
+
+	var packages []string
+	for _, field := range iface.Methods.List {
+		m, used, err := parseMethod(fset, field)
+		if err != nil {
+			return service{}, nil, false, err
+		}
+
+		svc.Methods = append(svc.Methods, m)
+		packages = append(packages, used...)
+	}
+
+	return svc, packages, true, nil
+}
+
+// importName returns the name of import which is the last element of path if it isn't renamed.
+func importName(spec *ast.ImportSpec) (name string, path string) {
+	path, _ = strconv.Unquote(spec.Path.Value)
+	if spec.Name != nil {
+		return spec.Name.Name, path
+	}
+
+	return path[strings.LastIndex(path, "/")+1:], path
+}
+
+// parseFile parses the services annotated in source.
+func parseFile(filename string, src []byte) (*file, error) {
+	fset := token.NewFileSet()
+
+	astFile, err := parser.ParseFile(fset, filename, src, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &file{Package: astFile.Name.Name}
+
+	var packages []string
+	for _, decl := range astFile.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range genDecl.Specs {
+			svc, used, ok, err := parseService(fset, genDecl, spec.(*ast.TypeSpec))
+			if err != nil {
+				return nil, err
+			}
+
+			if ok {
+				f.Services = append(f.Services, svc)
+				packages = append(packages, used...)
+			}
+		}
+	}
+
+	for _, spec := range astFile.Imports {
+		name, path := importName(spec)
+		if path == "context" || path == vexPath || !slices.Contains(packages, name) {
+			continue
+		}
+
+		imported := strconv.Quote(path)
+		if spec.Name != nil {
+			imported = name + " " + imported
+		}
+
+		// Packages without a dot in the first element are from the standard library.
+		if first, _, _ := strings.Cut(path, "/"); strings.Contains(first, ".") {
+			f.Imports = append(f.Imports, imported)
+		} else {
+			f.StdImports = append(f.StdImports, imported)
+		}
+	}
+
+	return f, nil
+}
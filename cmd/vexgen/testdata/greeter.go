@@ -0,0 +1,46 @@
+package greeter
+
+import (
+	"context"
+	"io"
+	"time"
+
+	pkgerrors "errors"
+
+	rego "github.com/FishGoddess/rego"
+	slog "log/slog"
+)
+
+type HelloRequest struct {
+	Name string
+}
+
+type HelloResponse struct {
+	Greeting string
+	Time     time.Time
+}
+
+type Level = slog.Level
+
+// Greeter greets people.
+//
+//vex:service
+type Greeter interface {
+	SayHello(ctx context.Context, req *HelloRequest) (*HelloResponse, error)
+	SayHi(ctx context.Context, name string) (greeting string, err error)
+}
+
+// Clock tells the time.
+//
+//vex:service clock
+type Clock interface {
+	Now(context.Context, struct{}) (time.Time, error)
+	Pool(ctx context.Context, level Level) (*rego.Pool[int], error)
+}
+
+// Closer isn't a service.
+type Closer interface {
+	io.Closer
+}
+
+var _ = pkgerrors.New
@@ -0,0 +1,107 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vex
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+)
+
+// ContentType is the type of data carried in packet which tells the codec of data.
+// Zero means the data isn't encoded by any codec, and you can use values >= 128 for your own codecs.
+type ContentType uint8
+
+const (
+	ContentTypeRaw  ContentType = 1
+	ContentTypeJSON ContentType = 2
+	ContentTypeGob  ContentType = 3
+)
+
+// Codec marshals and unmarshals the data in packet.
+type Codec interface {
+	// ContentType returns the content type of codec which is sent in packet.
+	ContentType() ContentType
+
+	// Marshal marshals v to data and returns an error if failed.
+	Marshal(v any) ([]byte, error)
+
+	// Unmarshal unmarshals data to v and returns an error if failed.
+	Unmarshal(data []byte, v any) error
+}
+
+// JSONCodec is a codec using encoding/json.
+type JSONCodec struct{}
+
+// ContentType returns the content type of json codec.
+func (JSONCodec) ContentType() ContentType {
+	return ContentTypeJSON
+}
+
+// Marshal marshals v to json.
+func (JSONCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal unmarshals json to v.
+func (JSONCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// GobCodec is a codec using encoding/gob.
+type GobCodec struct{}
+
+// ContentType returns the content type of gob codec.
+func (GobCodec) ContentType() ContentType {
+	return ContentTypeGob
+}
+
+// Marshal marshals v to gob.
+func (GobCodec) Marshal(v any) ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := gob.NewEncoder(&buffer).Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// Unmarshal unmarshals gob to v.
+func (GobCodec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// RawCodec is a codec passing bytes through without encoding.
+// It only supports []byte and *[]byte.
+type RawCodec struct{}
+
+// ContentType returns the content type of raw codec.
+func (RawCodec) ContentType() ContentType {
+	return ContentTypeRaw
+}
+
+// Marshal returns v directly if it's []byte or *[]byte.
+func (RawCodec) Marshal(v any) ([]byte, error) {
+	switch data := v.(type) {
+	case []byte:
+		return data, nil
+	case *[]byte:
+		return *data, nil
+	default:
+		return nil, fmt.Errorf("vex: raw codec can't marshal %T", v)
+	}
+}
+
+// Unmarshal sets data to v if it's *[]byte.
+func (RawCodec) Unmarshal(data []byte, v any) error {
+	ptr, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("vex: raw codec can't unmarshal to %T", v)
+	}
+
+	*ptr = data
+	return nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vex
+
+import (
+	"testing"
+)
+
+type testCodecData struct {
+	Name  string
+	Value int
+}
+
+// go test -v -cover -run=^TestCodecs$
+func TestCodecs(t *testing.T) {
+	testCases := []struct {
+		codec       Codec
+		contentType ContentType
+	}{
+		{codec: JSONCodec{}, contentType: ContentTypeJSON},
+		{codec: GobCodec{}, contentType: ContentTypeGob},
+	}
+
+	want := testCodecData{Name: "vex", Value: 123}
+
+	for _, testCase := range testCases {
+		if got := testCase.codec.ContentType(); got != testCase.contentType {
+			t.Fatalf("got %d != want %d", got, testCase.contentType)
+		}
+
+		data, err := testCase.codec.Marshal(want)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got testCodecData
+		if err = testCase.codec.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+
+		if got != want {
+			t.Fatalf("got %+v != want %+v", got, want)
+		}
+	}
+}
+
+// go test -v -cover -run=^TestRawCodec$
+func TestRawCodec(t *testing.T) {
+	codec := RawCodec{}
+	if got := codec.ContentType(); got != ContentTypeRaw {
+		t.Fatalf("got %d != want %d", got, ContentTypeRaw)
+	}
+
+	want := []byte("raw")
+
+	data, err := codec.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != string(want) {
+		t.Fatalf("got %s != want %s", data, want)
+	}
+
+	if data, err = codec.Marshal(&want); err != nil || string(data) != string(want) {
+		t.Fatalf("got (%s, %+v) != want (%s, nil)", data, err, want)
+	}
+
+	var got []byte
+	if err = codec.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != string(want) {
+		t.Fatalf("got %s != want %s", got, want)
+	}
+
+	if _, err = codec.Marshal("raw"); err == nil {
+		t.Fatal("marshal string returns a nil error")
+	}
+
+	var str string
+	if err = codec.Unmarshal(data, &str); err == nil {
+		t.Fatal("unmarshal to string returns a nil error")
+	}
+}
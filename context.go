@@ -6,10 +6,24 @@ package vex
 
 import (
 	"context"
+	"crypto/x509"
 	"net"
 	"sync"
 )
 
+type methodKey struct{}
+
+// ContextWithMethod returns a new context carrying the method which will be sent by client.
+func ContextWithMethod(ctx context.Context, method string) context.Context {
+	return context.WithValue(ctx, methodKey{}, method)
+}
+
+// MethodFromContext returns the method carried by context.
+func MethodFromContext(ctx context.Context) string {
+	method, _ := ctx.Value(methodKey{}).(string)
+	return method
+}
+
 var contextPool = sync.Pool{
 	New: func() any {
 		return new(Context)
@@ -28,6 +42,12 @@ func releaseContext(ctx *Context) {
 	ctx.Context = nil
 	ctx.localAddress = ""
 	ctx.remoteAddress = ""
+	ctx.method = ""
+	ctx.metadata = nil
+	ctx.responseMetadata = nil
+	ctx.peerCertificate = nil
+	ctx.contentType = 0
+	ctx.responseContentType = 0
 
 	contextPool.Put(ctx)
 }
@@ -38,6 +58,14 @@ type Context struct {
 
 	localAddress  string
 	remoteAddress string
+	method        string
+
+	metadata         Metadata
+	responseMetadata Metadata
+	peerCertificate  *x509.Certificate
+
+	contentType         ContentType
+	responseContentType ContentType
 }
 
 // LocalAddress returns the address of server.
@@ -49,3 +77,33 @@ func (c *Context) LocalAddress() string {
 func (c *Context) RemoteAddress() string {
 	return c.remoteAddress
 }
+
+// PeerCertificate returns the certificate of client which is nil if tls isn't used or client sends no certificate.
+// Use its subject to authorize the client if needed.
+func (c *Context) PeerCertificate() *x509.Certificate {
+	return c.peerCertificate
+}
+
+// ContentType returns the content type of request data which is zero if it isn't encoded by a codec.
+func (c *Context) ContentType() ContentType {
+	return c.contentType
+}
+
+// Method returns the method called by client.
+func (c *Context) Method() string {
+	return c.method
+}
+
+// Metadata returns the metadata sent by client.
+func (c *Context) Metadata() Metadata {
+	return c.metadata
+}
+
+// SetResponseMetadata sets a key/value pair to the metadata which will be responded to client.
+func (c *Context) SetResponseMetadata(key string, value string) {
+	if c.responseMetadata == nil {
+		c.responseMetadata = make(Metadata, 4)
+	}
+
+	c.responseMetadata[key] = value
+}
@@ -14,11 +14,20 @@ import (
 func TestContext(t *testing.T) {
 	parentCtx := context.Background()
 
-	conn, err := net.Dial("tcp", "www.google.com:80")
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
 	if err != nil {
 		t.Fatal(err)
 	}
 
+	defer listener.Close()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer conn.Close()
+
 	ctx := acquireContext(parentCtx, conn)
 	if ctx.Context != parentCtx {
 		t.Fatalf("got %+v != want %+v", ctx.Context, parentCtx)
@@ -34,6 +43,21 @@ func TestContext(t *testing.T) {
 		t.Fatalf("got %s != want %s", ctx.remoteAddress, remoteAddress)
 	}
 
+	ctx.method = "method"
+	if ctx.Method() != "method" {
+		t.Fatalf("got %s != want %s", ctx.Method(), "method")
+	}
+
+	ctx.metadata = Metadata{"key": "value"}
+	if ctx.Metadata()["key"] != "value" {
+		t.Fatalf("got %+v is wrong", ctx.Metadata())
+	}
+
+	ctx.SetResponseMetadata("key", "value")
+	if ctx.responseMetadata["key"] != "value" {
+		t.Fatalf("got %+v is wrong", ctx.responseMetadata)
+	}
+
 	releaseContext(ctx)
 	if ctx.Context != nil {
 		t.Fatalf("got %+v != nil", ctx.Context)
@@ -46,4 +70,29 @@ func TestContext(t *testing.T) {
 	if ctx.remoteAddress != "" {
 		t.Fatalf("got %+v != ''", ctx.remoteAddress)
 	}
+
+	if ctx.method != "" {
+		t.Fatalf("got %+v != ''", ctx.method)
+	}
+
+	if ctx.metadata != nil {
+		t.Fatalf("got %+v != nil", ctx.metadata)
+	}
+
+	if ctx.responseMetadata != nil {
+		t.Fatalf("got %+v != nil", ctx.responseMetadata)
+	}
+}
+
+// go test -v -cover -run=^TestContextWithMethod$
+func TestContextWithMethod(t *testing.T) {
+	ctx := context.Background()
+	if method := MethodFromContext(ctx); method != "" {
+		t.Fatalf("got %s != want ''", method)
+	}
+
+	ctx = ContextWithMethod(ctx, "method")
+	if method := MethodFromContext(ctx); method != "method" {
+		t.Fatalf("got %s != want %s", method, "method")
+	}
 }
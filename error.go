@@ -0,0 +1,129 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vex
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	packets "github.com/FishGoddess/vex/internal/packet"
+)
+
+// Code is the code of error which is carried in packet.
+type Code uint32
+
+// These are some well-known codes, and you can define your own codes if needed.
+const (
+	CodeUnknown        Code = 0
+	CodeBadRequest     Code = 400
+	CodeUnauthorized   Code = 401
+	CodeForbidden      Code = 403
+	CodeNotFound       Code = 404
+	CodeTimeout        Code = 408
+	CodeRateLimited    Code = 429
+	CodeCanceled       Code = 499
+	CodeInternal       Code = 500
+	CodeMethodNotFound Code = 501
+	CodeUnavailable    Code = 503
+)
+
+// These are some well-known errors which can be checked by errors.Is.
+var (
+	ErrBadRequest     = NewError(CodeBadRequest, "vex: bad request")
+	ErrUnauthorized   = NewError(CodeUnauthorized, "vex: unauthorized")
+	ErrForbidden      = NewError(CodeForbidden, "vex: forbidden")
+	ErrNotFound       = NewError(CodeNotFound, "vex: not found")
+	ErrTimeout        = NewError(CodeTimeout, "vex: timeout")
+	ErrRateLimited    = NewError(CodeRateLimited, "vex: rate limited")
+	ErrCanceled       = NewError(CodeCanceled, "vex: canceled")
+	ErrInternal       = NewError(CodeInternal, "vex: internal error")
+	ErrMethodNotFound = NewError(CodeMethodNotFound, "vex: method not found")
+	ErrUnavailable    = NewError(CodeUnavailable, "vex: unavailable")
+)
+
+// Error is an error with code which can be sent from server to client.
+// Handlers can return it directly and clients can use errors.As or errors.Is to check it.
+type Error struct {
+	Code    Code
+	Message string
+	Details []byte
+}
+
+// NewError returns a new error with code and message.
+func NewError(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// WithDetails returns a copy of error with details.
+func (e *Error) WithDetails(details []byte) *Error {
+	err := *e
+	err.Details = details
+	return &err
+}
+
+// Error returns the message of error.
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return "vex: error code " + strconv.FormatUint(uint64(e.Code), 10)
+	}
+
+	return e.Message
+}
+
+// Is reports whether the target is an error with the same code.
+func (e *Error) Is(target error) bool {
+	err, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+
+	return e.Code == err.Code
+}
+
+// CodeOf returns the code of err which is CodeUnknown if err isn't an *Error.
+func CodeOf(err error) Code {
+	var vexErr *Error
+	if errors.As(err, &vexErr) {
+		return vexErr.Code
+	}
+
+	return CodeUnknown
+}
+
+func setPacketError(packet *packets.Packet, err error) {
+	packet.SetError(err)
+
+	var vexErr *Error
+	if errors.As(err, &vexErr) {
+		packet.SetErrorCode(uint32(vexErr.Code))
+		packet.SetErrorDetails(vexErr.Details)
+		return
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		packet.SetErrorCode(uint32(CodeTimeout))
+		return
+	}
+
+	if errors.Is(err, context.Canceled) {
+		packet.SetErrorCode(uint32(CodeCanceled))
+	}
+}
+
+func packetData(packet *packets.Packet) ([]byte, error) {
+	data, err := packet.Data()
+	if err != nil {
+		err = &Error{
+			Code:    Code(packet.ErrorCode()),
+			Message: err.Error(),
+			Details: packet.ErrorDetails(),
+		}
+
+		return nil, err
+	}
+
+	return data, nil
+}
@@ -0,0 +1,166 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vex
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	packets "github.com/FishGoddess/vex/internal/packet"
+)
+
+// go test -v -cover -run=^TestError$
+func TestError(t *testing.T) {
+	err := NewError(CodeNotFound, "user not found")
+	if err.Error() != "user not found" {
+		t.Fatalf("got %s != want %s", err.Error(), "user not found")
+	}
+
+	detailsErr := err.WithDetails([]byte("details"))
+	if err.Details != nil {
+		t.Fatalf("got %+v != want nil", err.Details)
+	}
+
+	if string(detailsErr.Details) != "details" {
+		t.Fatalf("got %s != want %s", detailsErr.Details, "details")
+	}
+
+	err = &Error{Code: CodeInternal}
+	if err.Error() != "vex: error code 500" {
+		t.Fatalf("got %s != want %s", err.Error(), "vex: error code 500")
+	}
+}
+
+// go test -v -cover -run=^TestErrorIs$
+func TestErrorIs(t *testing.T) {
+	err := fmt.Errorf("load user: %w", NewError(CodeNotFound, "user not found"))
+
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("error %+v is not %+v", err, ErrNotFound)
+	}
+
+	if errors.Is(err, ErrInternal) {
+		t.Fatalf("error %+v is %+v", err, ErrInternal)
+	}
+
+	if errors.Is(err, io.EOF) {
+		t.Fatalf("error %+v is %+v", err, io.EOF)
+	}
+}
+
+// go test -v -cover -run=^TestCodeOf$
+func TestCodeOf(t *testing.T) {
+	testCases := map[error]Code{
+		nil:                                CodeUnknown,
+		io.EOF:                             CodeUnknown,
+		ErrRateLimited:                     CodeRateLimited,
+		fmt.Errorf("wrap: %w", ErrTimeout): CodeTimeout,
+	}
+
+	for err, want := range testCases {
+		if got := CodeOf(err); got != want {
+			t.Fatalf("error %+v: got %d != want %d", err, got, want)
+		}
+	}
+}
+
+// go test -v -cover -run=^TestPacketError$
+func TestPacketError(t *testing.T) {
+	testCases := []struct {
+		err  error
+		want *Error
+	}{
+		{
+			err:  io.EOF,
+			want: &Error{Code: CodeUnknown, Message: io.EOF.Error()},
+		},
+		{
+			err:  context.DeadlineExceeded,
+			want: &Error{Code: CodeTimeout, Message: context.DeadlineExceeded.Error()},
+		},
+		{
+			err:  fmt.Errorf("wrap: %w", context.Canceled),
+			want: &Error{Code: CodeCanceled, Message: "wrap: " + context.Canceled.Error()},
+		},
+		{
+			err:  fmt.Errorf("wrap: %w", ErrNotFound.WithDetails([]byte("details"))),
+			want: &Error{Code: CodeNotFound, Message: "wrap: vex: not found", Details: []byte("details")},
+		},
+	}
+
+	for _, testCase := range testCases {
+		packet := packets.New(1)
+		setPacketError(&packet, testCase.err)
+
+		buffer := bytes.NewBuffer(nil)
+		if err := packets.WritePacket(buffer, packet); err != nil {
+			t.Fatal(err)
+		}
+
+		packet, err := packets.ReadPacket(buffer)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = packetData(&packet)
+
+		got := fmt.Sprintf("%+v", err)
+		want := fmt.Sprintf("%+v", testCase.want)
+		if got != want {
+			t.Fatalf("got %s != want %s", got, want)
+		}
+	}
+}
+
+// go test -v -cover -run=^TestErrorServer$
+func TestErrorServer(t *testing.T) {
+	handler := HandlerFunc(func(ctx *Context, data []byte) ([]byte, error) {
+		err := ErrBadRequest.WithDetails(data)
+		return nil, fmt.Errorf("check data: %w", err)
+	})
+
+	svr := NewServer("127.0.0.1:0", handler)
+
+	go func() {
+		if err := svr.Serve(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	defer svr.Close()
+
+	time.Sleep(100 * time.Millisecond)
+	address := svr.(*server).listener.Addr().String()
+
+	client, err := NewClient(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	_, err = client.Send(context.Background(), []byte("details"))
+	if !errors.Is(err, ErrBadRequest) {
+		t.Fatalf("error %+v is not %+v", err, ErrBadRequest)
+	}
+
+	var vexErr *Error
+	if !errors.As(err, &vexErr) {
+		t.Fatalf("error %+v is not *Error", err)
+	}
+
+	if vexErr.Message != "check data: vex: bad request" {
+		t.Fatalf("got %s != want %s", vexErr.Message, "check data: vex: bad request")
+	}
+
+	if string(vexErr.Details) != "details" {
+		t.Fatalf("got %s != want %s", vexErr.Details, "details")
+	}
+}
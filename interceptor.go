@@ -0,0 +1,48 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vex
+
+import "context"
+
+// ServerInterceptor intercepts the handling of server.
+// It should call handler to continue handling or return directly to interrupt it.
+type ServerInterceptor func(ctx *Context, data []byte, handler Handler) ([]byte, error)
+
+// SendFunc is the function sending data and getting a new data.
+type SendFunc func(ctx context.Context, data []byte) ([]byte, error)
+
+// ClientInterceptor intercepts the sending of client.
+// It should call send to continue sending or return directly to interrupt it.
+type ClientInterceptor func(ctx context.Context, data []byte, send SendFunc) ([]byte, error)
+
+// chainServerInterceptors wraps handler with interceptors.
+// The first interceptor is the outermost one, which means it is called first.
+func chainServerInterceptors(handler Handler, interceptors []ServerInterceptor) Handler {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor := interceptors[i]
+		next := handler
+
+		handler = HandlerFunc(func(ctx *Context, data []byte) ([]byte, error) {
+			return interceptor(ctx, data, next)
+		})
+	}
+
+	return handler
+}
+
+// chainClientInterceptors wraps send with interceptors.
+// The first interceptor is the outermost one, which means it is called first.
+func chainClientInterceptors(send SendFunc, interceptors []ClientInterceptor) SendFunc {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor := interceptors[i]
+		next := send
+
+		send = func(ctx context.Context, data []byte) ([]byte, error) {
+			return interceptor(ctx, data, next)
+		}
+	}
+
+	return send
+}
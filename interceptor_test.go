@@ -0,0 +1,139 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vex
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// go test -v -cover -run=^TestChainServerInterceptors$
+func TestChainServerInterceptors(t *testing.T) {
+	var calls []string
+
+	newInterceptor := func(name string) ServerInterceptor {
+		return func(ctx *Context, data []byte, handler Handler) ([]byte, error) {
+			calls = append(calls, name+" before")
+			defer func() { calls = append(calls, name+" after") }()
+
+			return handler.Handle(ctx, append(data, name...))
+		}
+	}
+
+	handler := HandlerFunc(func(ctx *Context, data []byte) ([]byte, error) {
+		calls = append(calls, "handler")
+		return data, nil
+	})
+
+	interceptors := []ServerInterceptor{newInterceptor("1"), newInterceptor("2")}
+	handler = chainServerInterceptors(handler, interceptors).(HandlerFunc)
+
+	data, err := handler.Handle(new(Context), []byte("0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "012" {
+		t.Fatalf("got %s != want %s", data, "012")
+	}
+
+	want := []string{"1 before", "2 before", "handler", "2 after", "1 after"}
+	if !slices.Equal(calls, want) {
+		t.Fatalf("got %+v != want %+v", calls, want)
+	}
+}
+
+// go test -v -cover -run=^TestChainClientInterceptors$
+func TestChainClientInterceptors(t *testing.T) {
+	var calls []string
+
+	newInterceptor := func(name string) ClientInterceptor {
+		return func(ctx context.Context, data []byte, send SendFunc) ([]byte, error) {
+			calls = append(calls, name+" before")
+			defer func() { calls = append(calls, name+" after") }()
+
+			return send(ctx, append(data, name...))
+		}
+	}
+
+	send := func(ctx context.Context, data []byte) ([]byte, error) {
+		calls = append(calls, "send")
+		return data, nil
+	}
+
+	interceptors := []ClientInterceptor{newInterceptor("1"), newInterceptor("2")}
+	send = chainClientInterceptors(send, interceptors)
+
+	data, err := send(context.Background(), []byte("0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "012" {
+		t.Fatalf("got %s != want %s", data, "012")
+	}
+
+	want := []string{"1 before", "2 before", "send", "2 after", "1 after"}
+	if !slices.Equal(calls, want) {
+		t.Fatalf("got %+v != want %+v", calls, want)
+	}
+}
+
+// go test -v -cover -run=^TestInterceptors$
+func TestInterceptors(t *testing.T) {
+	errDenied := errors.New("denied")
+
+	serverInterceptor := func(ctx *Context, data []byte, handler Handler) ([]byte, error) {
+		if string(data) == "deny" {
+			return nil, errDenied
+		}
+
+		return handler.Handle(ctx, data)
+	}
+
+	svr := NewServer("127.0.0.1:0", new(testHandler), WithServerInterceptors(serverInterceptor))
+
+	go func() {
+		if err := svr.Serve(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	defer svr.Close()
+
+	time.Sleep(100 * time.Millisecond)
+	address := svr.(*server).listener.Addr().String()
+
+	clientInterceptor := func(ctx context.Context, data []byte, send SendFunc) ([]byte, error) {
+		data, err := send(ctx, data)
+		return append(data, '!'), err
+	}
+
+	client, err := NewClient(address, WithClientInterceptors(clientInterceptor))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	ctx := context.Background()
+
+	data, err := client.Send(ctx, []byte("allow"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "allow!" {
+		t.Fatalf("got %s != want %s", data, "allow!")
+	}
+
+	_, err = client.Send(ctx, []byte("deny"))
+	if err == nil || err.Error() != errDenied.Error() {
+		t.Fatalf("got %+v != want %+v", err, errDenied)
+	}
+}
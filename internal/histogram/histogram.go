@@ -0,0 +1,85 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package histogram
+
+import (
+	"slices"
+	"sync"
+)
+
+// Value is the type of values observed by histogram, like time.Duration and seconds in float64.
+type Value interface {
+	~int64 | ~float64
+}
+
+// Snapshot is the snapshot of a histogram.
+type Snapshot[T Value] struct {
+	// Buckets are the upper bounds of buckets.
+	Buckets []T
+
+	// Counts are the cumulative counts of buckets, which means Counts[i] is the count of values <= Buckets[i].
+	Counts []uint64
+
+	// Count is the count of all values and Sum is the sum of them.
+	Count uint64
+	Sum   T
+}
+
+// Histogram records values in buckets and it's safe for concurrent use.
+type Histogram[T Value] struct {
+	buckets []T
+	counts  []uint64
+	count   uint64
+	sum     T
+
+	lock sync.Mutex
+}
+
+// New returns a histogram with the upper bounds of buckets which should be sorted.
+func New[T Value](buckets []T) *Histogram[T] {
+	h := &Histogram[T]{
+		buckets: buckets,
+		counts:  make([]uint64, len(buckets)),
+	}
+
+	return h
+}
+
+// Observe records the value in the first bucket not less than it.
+func (h *Histogram[T]) Observe(value T) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	// The counts aren't cumulative here so observing only updates one bucket.
+	if i, _ := slices.BinarySearch(h.buckets, value); i < len(h.buckets) {
+		h.counts[i]++
+	}
+
+	h.count++
+	h.sum += value
+}
+
+// Snapshot returns the snapshot of histogram with cumulative counts.
+func (h *Histogram[T]) Snapshot() Snapshot[T] {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	counts := make([]uint64, len(h.counts))
+
+	var count uint64
+	for i, c := range h.counts {
+		count += c
+		counts[i] = count
+	}
+
+	snapshot := Snapshot[T]{
+		Buckets: h.buckets,
+		Counts:  counts,
+		Count:   h.count,
+		Sum:     h.sum,
+	}
+
+	return snapshot
+}
@@ -0,0 +1,40 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package histogram
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// go test -v -cover -run=^TestHistogram$
+func TestHistogram(t *testing.T) {
+	h := New([]time.Duration{time.Millisecond, time.Second})
+
+	snapshot := h.Snapshot()
+	if !slices.Equal(snapshot.Counts, []uint64{0, 0}) || snapshot.Count != 0 || snapshot.Sum != 0 {
+		t.Fatalf("got %+v is wrong", snapshot)
+	}
+
+	h.Observe(time.Millisecond)
+	h.Observe(30 * time.Millisecond)
+	h.Observe(time.Minute)
+
+	snapshot = h.Snapshot()
+
+	want := []uint64{1, 2}
+	if !slices.Equal(snapshot.Counts, want) {
+		t.Fatalf("got %+v != want %+v", snapshot.Counts, want)
+	}
+
+	if snapshot.Count != 3 {
+		t.Fatalf("got %d != want 3", snapshot.Count)
+	}
+
+	if snapshot.Sum != time.Minute+31*time.Millisecond {
+		t.Fatalf("got %s != want %s", snapshot.Sum, time.Minute+31*time.Millisecond)
+	}
+}
@@ -4,18 +4,46 @@
 
 package packet
 
-import "errors"
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+)
 
 const (
-	flagError = 0x1
+	flagError        = 0x1
+	flagMethod       = 0x2
+	flagErrorDetails = 0x4
+	flagMetadata     = 0x8
+	flagTimeout      = 0x10
+	flagCancel       = 0x20
+	flagPing         = 0x40
+	flagPong         = 0x80
+	flagGoAway       = 0x100
+	flagStream       = 0x200
+	flagEndOfStream  = 0x400
+	flagWindow       = 0x800
+	flagOpenStream   = 0x1000
+	flagOneWay       = 0x2000
+)
+
+const (
+	contentTypeShift = 16
+	contentTypeMask  = 0xFF << contentTypeShift
+	errorCodeShift   = 32
+	errorCodeMask    = 1<<errorCodeShift - 1
 )
 
 type Packet struct {
-	id     uint64
-	magic  uint32
-	flags  uint64
-	length uint32
-	data   []byte
+	id       uint64
+	magic    uint32
+	flags    uint64
+	length   uint32
+	method   string
+	details  []byte
+	metadata map[string]string
+	timeout  time.Duration
+	data     []byte
 }
 
 // New returns a new packet with id.
@@ -33,6 +61,75 @@ func (p *Packet) ID() uint64 {
 	return p.id
 }
 
+// Method returns the method of packet.
+func (p *Packet) Method() string {
+	return p.method
+}
+
+// Metadata returns the metadata of packet.
+func (p *Packet) Metadata() map[string]string {
+	return p.metadata
+}
+
+// Timeout returns the timeout of packet which is zero if not set.
+func (p *Packet) Timeout() time.Duration {
+	return p.timeout
+}
+
+// IsCancel returns if the packet is a cancel packet which cancels the request with the same id.
+func (p *Packet) IsCancel() bool {
+	return p.flagSet(flagCancel)
+}
+
+// IsPing returns if the packet is a ping packet which should be responded with a pong packet.
+func (p *Packet) IsPing() bool {
+	return p.flagSet(flagPing)
+}
+
+// IsPong returns if the packet is a pong packet.
+func (p *Packet) IsPong() bool {
+	return p.flagSet(flagPong)
+}
+
+// IsGoAway returns if the packet is a go away packet which means the server is shutting down.
+func (p *Packet) IsGoAway() bool {
+	return p.flagSet(flagGoAway)
+}
+
+// IsStream returns if the packet belongs to a stream which may have several packets with the same id.
+func (p *Packet) IsStream() bool {
+	return p.flagSet(flagStream)
+}
+
+// IsEndOfStream returns if the packet is the last packet of a stream.
+func (p *Packet) IsEndOfStream() bool {
+	return p.flagSet(flagEndOfStream)
+}
+
+// IsOpenStream returns if the packet opens a stream with its method, metadata and timeout.
+func (p *Packet) IsOpenStream() bool {
+	return p.flagSet(flagOpenStream)
+}
+
+// IsWindow returns if the packet is a window packet which grows the flow control window of a stream.
+func (p *Packet) IsWindow() bool {
+	return p.flagSet(flagWindow)
+}
+
+// Window returns the increment of window carried by a window packet which is zero if not set.
+func (p *Packet) Window() uint32 {
+	if !p.flagSet(flagWindow) || len(p.data) < 4 {
+		return 0
+	}
+
+	return binary.BigEndian.Uint32(p.data)
+}
+
+// IsOneWay returns if the packet is a one way packet which shouldn't be responded.
+func (p *Packet) IsOneWay() bool {
+	return p.flagSet(flagOneWay)
+}
+
 // Data returns the data of packet and returns an error if it's an error packet.
 func (p *Packet) Data() ([]byte, error) {
 	if p.flagSet(flagError) {
@@ -43,10 +140,111 @@ func (p *Packet) Data() ([]byte, error) {
 	return p.data, nil
 }
 
+// ErrorCode returns the error code of packet which is zero if not set.
+func (p *Packet) ErrorCode() uint32 {
+	return uint32(p.flags >> errorCodeShift)
+}
+
+// ErrorDetails returns the error details of packet.
+func (p *Packet) ErrorDetails() []byte {
+	return p.details
+}
+
+// ContentType returns the content type of packet which is zero if not set.
+func (p *Packet) ContentType() uint8 {
+	return uint8((p.flags & contentTypeMask) >> contentTypeShift)
+}
+
 func (p *Packet) setFlag(flag uint64) {
 	p.flags = p.flags | flag
 }
 
+func (p *Packet) unsetFlag(flag uint64) {
+	p.flags = p.flags &^ flag
+}
+
+// SetMethod sets the method to packet.
+func (p *Packet) SetMethod(method string) {
+	if method == "" {
+		p.unsetFlag(flagMethod)
+	} else {
+		p.setFlag(flagMethod)
+	}
+
+	p.method = method
+}
+
+// SetMetadata sets the metadata to packet.
+func (p *Packet) SetMetadata(metadata map[string]string) {
+	if len(metadata) == 0 {
+		p.unsetFlag(flagMetadata)
+	} else {
+		p.setFlag(flagMetadata)
+	}
+
+	p.metadata = metadata
+}
+
+// SetTimeout sets the timeout to packet.
+// The timeout is a duration instead of a deadline, so it won't be affected by the clock of different machines.
+func (p *Packet) SetTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		p.unsetFlag(flagTimeout)
+		p.timeout = 0
+		return
+	}
+
+	p.setFlag(flagTimeout)
+	p.timeout = timeout
+}
+
+// SetCancel sets the cancel flag to packet.
+func (p *Packet) SetCancel() {
+	p.setFlag(flagCancel)
+}
+
+// SetPing sets the ping flag to packet.
+func (p *Packet) SetPing() {
+	p.setFlag(flagPing)
+}
+
+// SetPong sets the pong flag to packet.
+func (p *Packet) SetPong() {
+	p.setFlag(flagPong)
+}
+
+// SetGoAway sets the go away flag to packet.
+func (p *Packet) SetGoAway() {
+	p.setFlag(flagGoAway)
+}
+
+// SetStream sets the stream flag to packet.
+func (p *Packet) SetStream() {
+	p.setFlag(flagStream)
+}
+
+// SetEndOfStream sets the end of stream flag to packet.
+func (p *Packet) SetEndOfStream() {
+	p.setFlag(flagEndOfStream)
+}
+
+// SetOpenStream sets the open stream flag to packet.
+func (p *Packet) SetOpenStream() {
+	p.setFlag(flagOpenStream)
+}
+
+// SetWindow sets the window flag and the increment of window to packet.
+// The increment is stored in data, so it shouldn't be used with other data.
+func (p *Packet) SetWindow(increment uint32) {
+	p.setFlag(flagWindow)
+	p.SetData(binary.BigEndian.AppendUint32(nil, increment))
+}
+
+// SetOneWay sets the one way flag to packet.
+func (p *Packet) SetOneWay() {
+	p.setFlag(flagOneWay)
+}
+
 // SetData sets the data and its length to packet.
 func (p *Packet) SetData(data []byte) {
 	p.length = uint32(len(data))
@@ -62,3 +260,26 @@ func (p *Packet) SetError(err error) {
 	p.setFlag(flagError)
 	p.SetData([]byte(err.Error()))
 }
+
+// SetErrorCode sets the error code to packet.
+// The error code is stored in the high 32 bits of flags so it won't change the layout of packet.
+func (p *Packet) SetErrorCode(code uint32) {
+	p.flags = p.flags&errorCodeMask | uint64(code)<<errorCodeShift
+}
+
+// SetContentType sets the content type to packet.
+// The content type is stored in the bits 16-23 of flags so it won't change the layout of packet.
+func (p *Packet) SetContentType(contentType uint8) {
+	p.flags = p.flags&^contentTypeMask | uint64(contentType)<<contentTypeShift
+}
+
+// SetErrorDetails sets the error details to packet.
+func (p *Packet) SetErrorDetails(details []byte) {
+	if len(details) == 0 {
+		p.unsetFlag(flagErrorDetails)
+	} else {
+		p.setFlag(flagErrorDetails)
+	}
+
+	p.details = details
+}
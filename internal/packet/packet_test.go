@@ -5,9 +5,11 @@
 package packet
 
 import (
+	"fmt"
 	"io"
 	"slices"
 	"testing"
+	"time"
 )
 
 // go test -v -cover -run=^TestNew$
@@ -47,6 +49,170 @@ func TestPacketID(t *testing.T) {
 	}
 }
 
+// go test -v -cover -run=^TestPacketMethod$
+func TestPacketMethod(t *testing.T) {
+	packet := Packet{method: "method"}
+
+	if packet.Method() != "method" {
+		t.Fatalf("got %s is wrong", packet.Method())
+	}
+}
+
+// go test -v -cover -run=^TestPacketMetadata$
+func TestPacketMetadata(t *testing.T) {
+	packet := Packet{metadata: map[string]string{"key": "value"}}
+
+	got := fmt.Sprintf("%+v", packet.Metadata())
+	want := fmt.Sprintf("%+v", map[string]string{"key": "value"})
+	if got != want {
+		t.Fatalf("got %s != want %s", got, want)
+	}
+}
+
+// go test -v -cover -run=^TestPacketTimeout$
+func TestPacketTimeout(t *testing.T) {
+	packet := Packet{timeout: time.Second}
+
+	if packet.Timeout() != time.Second {
+		t.Fatalf("got %d is wrong", packet.Timeout())
+	}
+}
+
+// go test -v -cover -run=^TestPacketIsCancel$
+func TestPacketIsCancel(t *testing.T) {
+	packet := Packet{flags: 0}
+	if packet.IsCancel() {
+		t.Fatal("packet is cancel")
+	}
+
+	packet = Packet{flags: flagCancel}
+	if !packet.IsCancel() {
+		t.Fatal("packet isn't cancel")
+	}
+}
+
+// go test -v -cover -run=^TestPacketIsPing$
+func TestPacketIsPing(t *testing.T) {
+	packet := Packet{flags: 0}
+	if packet.IsPing() {
+		t.Fatal("packet is ping")
+	}
+
+	packet = Packet{flags: flagPing}
+	if !packet.IsPing() {
+		t.Fatal("packet isn't ping")
+	}
+}
+
+// go test -v -cover -run=^TestPacketIsPong$
+func TestPacketIsPong(t *testing.T) {
+	packet := Packet{flags: 0}
+	if packet.IsPong() {
+		t.Fatal("packet is pong")
+	}
+
+	packet = Packet{flags: flagPong}
+	if !packet.IsPong() {
+		t.Fatal("packet isn't pong")
+	}
+}
+
+// go test -v -cover -run=^TestPacketIsGoAway$
+func TestPacketIsGoAway(t *testing.T) {
+	packet := Packet{flags: 0}
+	if packet.IsGoAway() {
+		t.Fatal("packet is go away")
+	}
+
+	packet = Packet{flags: flagGoAway}
+	if !packet.IsGoAway() {
+		t.Fatal("packet isn't go away")
+	}
+}
+
+// go test -v -cover -run=^TestPacketIsStream$
+func TestPacketIsStream(t *testing.T) {
+	packet := Packet{flags: 0}
+	if packet.IsStream() {
+		t.Fatal("packet is stream")
+	}
+
+	packet = Packet{flags: flagStream}
+	if !packet.IsStream() {
+		t.Fatal("packet isn't stream")
+	}
+}
+
+// go test -v -cover -run=^TestPacketIsEndOfStream$
+func TestPacketIsEndOfStream(t *testing.T) {
+	packet := Packet{flags: 0}
+	if packet.IsEndOfStream() {
+		t.Fatal("packet is end of stream")
+	}
+
+	packet = Packet{flags: flagEndOfStream}
+	if !packet.IsEndOfStream() {
+		t.Fatal("packet isn't end of stream")
+	}
+}
+
+// go test -v -cover -run=^TestPacketIsOpenStream$
+func TestPacketIsOpenStream(t *testing.T) {
+	packet := Packet{flags: 0}
+	if packet.IsOpenStream() {
+		t.Fatal("packet is open stream")
+	}
+
+	packet = Packet{flags: flagOpenStream}
+	if !packet.IsOpenStream() {
+		t.Fatal("packet isn't open stream")
+	}
+}
+
+// go test -v -cover -run=^TestPacketIsWindow$
+func TestPacketIsWindow(t *testing.T) {
+	packet := Packet{flags: 0}
+	if packet.IsWindow() {
+		t.Fatal("packet is window")
+	}
+
+	packet = Packet{flags: flagWindow}
+	if !packet.IsWindow() {
+		t.Fatal("packet isn't window")
+	}
+}
+
+// go test -v -cover -run=^TestPacketWindow$
+func TestPacketWindow(t *testing.T) {
+	packet := Packet{flags: 0, data: []byte{0, 0, 1, 0}}
+	if got := packet.Window(); got != 0 {
+		t.Fatalf("got %d != want 0", got)
+	}
+
+	packet = Packet{flags: flagWindow, data: []byte{0, 0, 1}}
+	if got := packet.Window(); got != 0 {
+		t.Fatalf("got %d != want 0", got)
+	}
+
+	packet = Packet{flags: flagWindow, data: []byte{0, 0, 1, 0}}
+	if got := packet.Window(); got != 256 {
+		t.Fatalf("got %d != want 256", got)
+	}
+}
+
+// go test -v -cover -run=^TestPacketIsOneWay$
+func TestPacketIsOneWay(t *testing.T) {
+	packet := Packet{flags: 0}
+	if packet.IsOneWay() {
+		t.Fatal("packet is one way")
+	}
+
+	packet = Packet{flags: flagOneWay}
+	if !packet.IsOneWay() {
+		t.Fatal("packet isn't one way")
+	}
+}
+
 // go test -v -cover -run=^TestPacketData$
 func TestPacketData(t *testing.T) {
 	data := []byte("欲买桂花同载酒")
@@ -75,6 +241,33 @@ func TestPacketData(t *testing.T) {
 	}
 }
 
+// go test -v -cover -run=^TestPacketErrorCode$
+func TestPacketErrorCode(t *testing.T) {
+	packet := Packet{flags: 500<<errorCodeShift | flagError}
+
+	if packet.ErrorCode() != 500 {
+		t.Fatalf("got %d != want 500", packet.ErrorCode())
+	}
+}
+
+// go test -v -cover -run=^TestPacketContentType$
+func TestPacketContentType(t *testing.T) {
+	packet := Packet{flags: 2<<contentTypeShift | 500<<errorCodeShift | flagError}
+
+	if packet.ContentType() != 2 {
+		t.Fatalf("got %d != want 2", packet.ContentType())
+	}
+}
+
+// go test -v -cover -run=^TestPacketErrorDetails$
+func TestPacketErrorDetails(t *testing.T) {
+	packet := Packet{details: []byte("details")}
+
+	if string(packet.ErrorDetails()) != "details" {
+		t.Fatalf("got %s is wrong", packet.ErrorDetails())
+	}
+}
+
 // go test -v -cover -run=^TestPacketSetFlag$
 func TestPacketSetFlag(t *testing.T) {
 	flag1 := uint64(2)
@@ -91,6 +284,45 @@ func TestPacketSetFlag(t *testing.T) {
 	}
 }
 
+// go test -v -cover -run=^TestPacketUnsetFlag$
+func TestPacketUnsetFlag(t *testing.T) {
+	flag1 := uint64(2)
+	flag2 := uint64(16)
+
+	packet := Packet{flags: flag1 + flag2}
+	packet.unsetFlag(flag1)
+
+	got := packet.flags
+	want := flag2
+	if got != want {
+		t.Fatalf("got %d != want %d", got, want)
+	}
+}
+
+// go test -v -cover -run=^TestPacketSetMethod$
+func TestPacketSetMethod(t *testing.T) {
+	packet := Packet{flags: 0}
+	packet.SetMethod("method")
+
+	if packet.flags != flagMethod {
+		t.Fatalf("got %d != want %d", packet.flags, flagMethod)
+	}
+
+	if packet.method != "method" {
+		t.Fatalf("got %s != want %s", packet.method, "method")
+	}
+
+	packet.SetMethod("")
+
+	if packet.flags != 0 {
+		t.Fatalf("got %d != want 0", packet.flags)
+	}
+
+	if packet.method != "" {
+		t.Fatalf("got %s != want ''", packet.method)
+	}
+}
+
 // go test -v -cover -run=^TestPacketSetData$
 func TestPacketSetData(t *testing.T) {
 	data := []byte("终不似少年游")
@@ -146,3 +378,219 @@ func TestPacketSetError(t *testing.T) {
 		t.Fatalf("got %+v != want %+v", got, want)
 	}
 }
+
+// go test -v -cover -run=^TestPacketSetErrorCode$
+func TestPacketSetErrorCode(t *testing.T) {
+	packet := Packet{flags: flagError | flagMethod}
+	packet.SetErrorCode(404)
+	packet.SetErrorCode(500)
+
+	got := packet.flags
+	want := uint64(500<<errorCodeShift | flagError | flagMethod)
+	if got != want {
+		t.Fatalf("got %d != want %d", got, want)
+	}
+
+	packet.SetErrorCode(0)
+
+	got = packet.flags
+	want = uint64(flagError | flagMethod)
+	if got != want {
+		t.Fatalf("got %d != want %d", got, want)
+	}
+}
+
+// go test -v -cover -run=^TestPacketSetContentType$
+func TestPacketSetContentType(t *testing.T) {
+	packet := Packet{flags: 500<<errorCodeShift | flagError | flagMethod}
+	packet.SetContentType(1)
+	packet.SetContentType(255)
+
+	got := packet.flags
+	want := uint64(255<<contentTypeShift | 500<<errorCodeShift | flagError | flagMethod)
+	if got != want {
+		t.Fatalf("got %d != want %d", got, want)
+	}
+
+	packet.SetContentType(0)
+
+	got = packet.flags
+	want = uint64(500<<errorCodeShift | flagError | flagMethod)
+	if got != want {
+		t.Fatalf("got %d != want %d", got, want)
+	}
+}
+
+// go test -v -cover -run=^TestPacketSetErrorDetails$
+func TestPacketSetErrorDetails(t *testing.T) {
+	packet := Packet{flags: flagError}
+	packet.SetErrorDetails([]byte("details"))
+
+	if packet.flags != flagError|flagErrorDetails {
+		t.Fatalf("got %d != want %d", packet.flags, flagError|flagErrorDetails)
+	}
+
+	if string(packet.details) != "details" {
+		t.Fatalf("got %s != want %s", packet.details, "details")
+	}
+
+	packet.SetErrorDetails(nil)
+
+	if packet.flags != flagError {
+		t.Fatalf("got %d != want %d", packet.flags, flagError)
+	}
+
+	if packet.details != nil {
+		t.Fatalf("got %+v != want nil", packet.details)
+	}
+}
+
+// go test -v -cover -run=^TestPacketSetMetadata$
+func TestPacketSetMetadata(t *testing.T) {
+	metadata := map[string]string{"key": "value"}
+
+	packet := Packet{flags: 0}
+	packet.SetMetadata(metadata)
+
+	if packet.flags != flagMetadata {
+		t.Fatalf("got %d != want %d", packet.flags, flagMetadata)
+	}
+
+	got := fmt.Sprintf("%+v", packet.metadata)
+	want := fmt.Sprintf("%+v", metadata)
+	if got != want {
+		t.Fatalf("got %s != want %s", got, want)
+	}
+
+	packet.SetMetadata(nil)
+
+	if packet.flags != 0 {
+		t.Fatalf("got %d != want 0", packet.flags)
+	}
+
+	if packet.metadata != nil {
+		t.Fatalf("got %+v != want nil", packet.metadata)
+	}
+}
+
+// go test -v -cover -run=^TestPacketSetTimeout$
+func TestPacketSetTimeout(t *testing.T) {
+	packet := Packet{flags: 0}
+	packet.SetTimeout(time.Second)
+
+	if packet.flags != flagTimeout {
+		t.Fatalf("got %d != want %d", packet.flags, flagTimeout)
+	}
+
+	if packet.timeout != time.Second {
+		t.Fatalf("got %d != want %d", packet.timeout, time.Second)
+	}
+
+	packet.SetTimeout(-time.Second)
+
+	if packet.flags != 0 {
+		t.Fatalf("got %d != want 0", packet.flags)
+	}
+
+	if packet.timeout != 0 {
+		t.Fatalf("got %d != want 0", packet.timeout)
+	}
+}
+
+// go test -v -cover -run=^TestPacketSetCancel$
+func TestPacketSetCancel(t *testing.T) {
+	packet := Packet{flags: 0}
+	packet.SetCancel()
+
+	if packet.flags != flagCancel {
+		t.Fatalf("got %d != want %d", packet.flags, flagCancel)
+	}
+}
+
+// go test -v -cover -run=^TestPacketSetPing$
+func TestPacketSetPing(t *testing.T) {
+	packet := Packet{flags: 0}
+	packet.SetPing()
+
+	if packet.flags != flagPing {
+		t.Fatalf("got %d != want %d", packet.flags, flagPing)
+	}
+}
+
+// go test -v -cover -run=^TestPacketSetPong$
+func TestPacketSetPong(t *testing.T) {
+	packet := Packet{flags: 0}
+	packet.SetPong()
+
+	if packet.flags != flagPong {
+		t.Fatalf("got %d != want %d", packet.flags, flagPong)
+	}
+}
+
+// go test -v -cover -run=^TestPacketSetStream$
+func TestPacketSetStream(t *testing.T) {
+	packet := Packet{flags: 0}
+	packet.SetStream()
+
+	if packet.flags != flagStream {
+		t.Fatalf("got %d != want %d", packet.flags, flagStream)
+	}
+}
+
+// go test -v -cover -run=^TestPacketSetEndOfStream$
+func TestPacketSetEndOfStream(t *testing.T) {
+	packet := Packet{flags: 0}
+	packet.SetEndOfStream()
+
+	if packet.flags != flagEndOfStream {
+		t.Fatalf("got %d != want %d", packet.flags, flagEndOfStream)
+	}
+}
+
+// go test -v -cover -run=^TestPacketSetOpenStream$
+func TestPacketSetOpenStream(t *testing.T) {
+	packet := Packet{flags: 0}
+	packet.SetOpenStream()
+
+	if packet.flags != flagOpenStream {
+		t.Fatalf("got %d != want %d", packet.flags, flagOpenStream)
+	}
+}
+
+// go test -v -cover -run=^TestPacketSetWindow$
+func TestPacketSetWindow(t *testing.T) {
+	packet := Packet{flags: 0}
+	packet.SetWindow(65536)
+
+	if packet.flags != flagWindow {
+		t.Fatalf("got %d != want %d", packet.flags, flagWindow)
+	}
+
+	if packet.length != 4 {
+		t.Fatalf("got %d != want 4", packet.length)
+	}
+
+	if got := packet.Window(); got != 65536 {
+		t.Fatalf("got %d != want 65536", got)
+	}
+}
+
+// go test -v -cover -run=^TestPacketSetOneWay$
+func TestPacketSetOneWay(t *testing.T) {
+	packet := Packet{flags: 0}
+	packet.SetOneWay()
+
+	if packet.flags != flagOneWay {
+		t.Fatalf("got %d != want %d", packet.flags, flagOneWay)
+	}
+}
+
+// go test -v -cover -run=^TestPacketSetGoAway$
+func TestPacketSetGoAway(t *testing.T) {
+	packet := Packet{flags: 0}
+	packet.SetGoAway()
+
+	if packet.flags != flagGoAway {
+		t.Fatalf("got %d != want %d", packet.flags, flagGoAway)
+	}
+}
@@ -8,6 +8,9 @@ import (
 	"encoding/binary"
 	"errors"
 	"io"
+	"maps"
+	"slices"
+	"time"
 )
 
 const (
@@ -16,15 +19,173 @@ const (
 )
 
 var (
-	maxDataBytes = uint32(1<<32 - 1) // 4GB
+	maxSectionBytes = 1<<16 - 1         // 64KB
+	maxDataBytes    = uint32(1<<32 - 1) // 4GB
 )
 
 var (
-	errWrongMagic   = errors.New("vex: magic is wrong")
-	errWrongLength  = errors.New("vex: length is wrong")
-	errDataTooLarge = errors.New("vex: data is too large")
+	errWrongMagic      = errors.New("vex: magic is wrong")
+	errWrongLength     = errors.New("vex: length is wrong")
+	errSectionTooLarge = errors.New("vex: section is too large")
+	errWrongMetadata   = errors.New("vex: metadata is wrong")
+	errDataTooLarge    = errors.New("vex: data is too large")
 )
 
+// readSection reads a section which has a 2 bytes length before its bytes.
+func readSection(reader io.Reader) ([]byte, error) {
+	var lengthBytes [2]byte
+
+	_, err := io.ReadFull(reader, lengthBytes[:])
+	if err != nil {
+		return nil, err
+	}
+
+	length := binary.BigEndian.Uint16(lengthBytes[:])
+	section := make([]byte, length)
+
+	_, err = io.ReadFull(reader, section)
+	if err != nil {
+		return nil, err
+	}
+
+	return section, nil
+}
+
+// decodeMetadata decodes metadata from section.
+// Each key and value has a 2 bytes length before its bytes.
+func decodeMetadata(section []byte) (map[string]string, error) {
+	metadata := make(map[string]string, 4)
+
+	next := func() (string, error) {
+		if len(section) < 2 {
+			return "", errWrongMetadata
+		}
+
+		length := int(binary.BigEndian.Uint16(section))
+		if len(section) < 2+length {
+			return "", errWrongMetadata
+		}
+
+		str := string(section[2 : 2+length])
+		section = section[2+length:]
+		return str, nil
+	}
+
+	for len(section) > 0 {
+		key, err := next()
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := next()
+		if err != nil {
+			return nil, err
+		}
+
+		metadata[key] = value
+	}
+
+	return metadata, nil
+}
+
+// encodeMetadata encodes metadata to section in the order of keys.
+func encodeMetadata(metadata map[string]string) []byte {
+	keys := slices.Sorted(maps.Keys(metadata))
+
+	var section []byte
+	for _, key := range keys {
+		section = appendSection(section, key)
+		section = appendSection(section, metadata[key])
+	}
+
+	return section
+}
+
+func readSections(reader io.Reader, packet *Packet) error {
+	if packet.flagSet(flagMethod) {
+		method, err := readSection(reader)
+		if err != nil {
+			return err
+		}
+
+		packet.method = string(method)
+	}
+
+	if packet.flagSet(flagErrorDetails) {
+		details, err := readSection(reader)
+		if err != nil {
+			return err
+		}
+
+		packet.details = details
+	}
+
+	if packet.flagSet(flagMetadata) {
+		section, err := readSection(reader)
+		if err != nil {
+			return err
+		}
+
+		packet.metadata, err = decodeMetadata(section)
+		if err != nil {
+			return err
+		}
+	}
+
+	if packet.flagSet(flagTimeout) {
+		var timeoutBytes [8]byte
+
+		_, err := io.ReadFull(reader, timeoutBytes[:])
+		if err != nil {
+			return err
+		}
+
+		packet.timeout = time.Duration(binary.BigEndian.Uint64(timeoutBytes[:]))
+	}
+
+	return nil
+}
+
+// appendSection appends a section with a 2 bytes length before its bytes.
+func appendSection[Section string | []byte](packetBytes []byte, section Section) []byte {
+	packetBytes = binary.BigEndian.AppendUint16(packetBytes, uint16(len(section)))
+	packetBytes = append(packetBytes, section...)
+	return packetBytes
+}
+
+func appendSections(packetBytes []byte, packet Packet) ([]byte, error) {
+	if packet.flagSet(flagMethod) {
+		if len(packet.method) > maxSectionBytes {
+			return nil, errSectionTooLarge
+		}
+
+		packetBytes = appendSection(packetBytes, packet.method)
+	}
+
+	if packet.flagSet(flagErrorDetails) {
+		if len(packet.details) > maxSectionBytes {
+			return nil, errSectionTooLarge
+		}
+
+		packetBytes = appendSection(packetBytes, packet.details)
+	}
+
+	if packet.flagSet(flagMetadata) {
+		section := encodeMetadata(packet.metadata)
+		if len(section) > maxSectionBytes {
+			return nil, errSectionTooLarge
+		}
+
+		packetBytes = appendSection(packetBytes, section)
+	}
+
+	if packet.flagSet(flagTimeout) {
+		packetBytes = binary.BigEndian.AppendUint64(packetBytes, uint64(packet.timeout))
+	}
+
+	return packetBytes, nil
+}
+
 // ReadPacket reads a packet from reader and returns an error if failed.
 func ReadPacket(reader io.Reader) (packet Packet, err error) {
 	header := make([]byte, headerBytes)
@@ -44,6 +205,10 @@ func ReadPacket(reader io.Reader) (packet Packet, err error) {
 		return packet, errWrongMagic
 	}
 
+	if err = readSections(reader, &packet); err != nil {
+		return packet, err
+	}
+
 	if packet.length <= 0 {
 		return packet, nil
 	}
@@ -79,6 +244,12 @@ func WritePacket(writer io.Writer, packet Packet) (err error) {
 	packetBytes = endian.AppendUint32(packetBytes, packet.magic)
 	packetBytes = endian.AppendUint64(packetBytes, packet.flags)
 	packetBytes = endian.AppendUint32(packetBytes, packet.length)
+
+	packetBytes, err = appendSections(packetBytes, packet)
+	if err != nil {
+		return err
+	}
+
 	packetBytes = append(packetBytes, packet.data...)
 
 	_, err = writer.Write(packetBytes)
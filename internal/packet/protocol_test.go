@@ -9,7 +9,9 @@ import (
 	"fmt"
 	"io"
 	"slices"
+	"strings"
 	"testing"
+	"time"
 )
 
 // go test -v -cover -run=^TestReadPacket$
@@ -58,6 +60,41 @@ func TestReadPacket(t *testing.T) {
 			packet:      Packet{id: 5, magic: magic, flags: 0, length: 3, data: []byte("ABC")},
 			err:         nil,
 		},
+		{
+			packetBytes: []byte{0, 0, 0, 0, 0, 0, 0, 5, 0x77, 0x14, 0x30, 0xCB, 0, 0, 0, 0, 0, 0, 0, 2, 0, 0, 0, 3, 0, 2},
+			packet:      Packet{id: 5, magic: magic, flags: 2, length: 3},
+			err:         io.EOF,
+		},
+		{
+			packetBytes: []byte{0, 0, 0, 0, 0, 0, 0, 5, 0x77, 0x14, 0x30, 0xCB, 0, 0, 0, 0, 0, 0, 0, 2, 0, 0, 0, 3, 0, 2, 'M', 'D', 'A', 'B', 'C'},
+			packet:      Packet{id: 5, magic: magic, flags: 2, length: 3, method: "MD", data: []byte("ABC")},
+			err:         nil,
+		},
+		{
+			packetBytes: []byte{0, 0, 0, 0, 0, 0, 0, 5, 0x77, 0x14, 0x30, 0xCB, 0, 0, 0, 0, 0, 0, 0, 7, 0, 0, 0, 3, 0, 2, 'M', 'D', 0, 1, 'D', 'A', 'B', 'C'},
+			packet:      Packet{id: 5, magic: magic, flags: 7, length: 3, method: "MD", details: []byte("D"), data: []byte("ABC")},
+			err:         nil,
+		},
+		{
+			packetBytes: []byte{0, 0, 0, 0, 0, 0, 0, 5, 0x77, 0x14, 0x30, 0xCB, 0, 0, 0, 0, 0, 0, 0, 8, 0, 0, 0, 0, 0, 4, 0, 1, 'K', 0},
+			packet:      Packet{id: 5, magic: magic, flags: 8, length: 0},
+			err:         errWrongMetadata,
+		},
+		{
+			packetBytes: []byte{0, 0, 0, 0, 0, 0, 0, 5, 0x77, 0x14, 0x30, 0xCB, 0, 0, 0, 0, 0, 0, 0, 8, 0, 0, 0, 3, 0, 8, 0, 1, 'K', 0, 3, 'V', 'A', 'L', 'A', 'B', 'C'},
+			packet:      Packet{id: 5, magic: magic, flags: 8, length: 3, metadata: map[string]string{"K": "VAL"}, data: []byte("ABC")},
+			err:         nil,
+		},
+		{
+			packetBytes: []byte{0, 0, 0, 0, 0, 0, 0, 5, 0x77, 0x14, 0x30, 0xCB, 0, 0, 0, 0, 0, 0, 0, 0x10, 0, 0, 0, 3, 0, 0, 0, 0, 0x3B, 0x9A},
+			packet:      Packet{id: 5, magic: magic, flags: 0x10, length: 3},
+			err:         io.ErrUnexpectedEOF,
+		},
+		{
+			packetBytes: []byte{0, 0, 0, 0, 0, 0, 0, 5, 0x77, 0x14, 0x30, 0xCB, 0, 0, 0, 0, 0, 0, 0, 0x10, 0, 0, 0, 3, 0, 0, 0, 0, 0x3B, 0x9A, 0xCA, 0, 'A', 'B', 'C'},
+			packet:      Packet{id: 5, magic: magic, flags: 0x10, length: 3, timeout: time.Second, data: []byte("ABC")},
+			err:         nil,
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -117,6 +154,41 @@ func TestWritePacket(t *testing.T) {
 			packet:      Packet{id: 5, magic: magic, flags: 0, length: 3, data: []byte("ABC")},
 			err:         nil,
 		},
+		{
+			packetBytes: []byte{},
+			packet:      Packet{id: 5, magic: magic, flags: 2, length: 0, method: strings.Repeat("M", maxSectionBytes+1)},
+			err:         errSectionTooLarge,
+		},
+		{
+			packetBytes: []byte{0, 0, 0, 0, 0, 0, 0, 5, 0x77, 0x14, 0x30, 0xCB, 0, 0, 0, 0, 0, 0, 0, 2, 0, 0, 0, 3, 0, 2, 'M', 'D', 'A', 'B', 'C'},
+			packet:      Packet{id: 5, magic: magic, flags: 2, length: 3, method: "MD", data: []byte("ABC")},
+			err:         nil,
+		},
+		{
+			packetBytes: []byte{},
+			packet:      Packet{id: 5, magic: magic, flags: 5, length: 0, details: make([]byte, maxSectionBytes+1)},
+			err:         errSectionTooLarge,
+		},
+		{
+			packetBytes: []byte{0, 0, 0, 0, 0, 0, 0, 5, 0x77, 0x14, 0x30, 0xCB, 0, 0, 0, 0, 0, 0, 0, 7, 0, 0, 0, 3, 0, 2, 'M', 'D', 0, 1, 'D', 'A', 'B', 'C'},
+			packet:      Packet{id: 5, magic: magic, flags: 7, length: 3, method: "MD", details: []byte("D"), data: []byte("ABC")},
+			err:         nil,
+		},
+		{
+			packetBytes: []byte{},
+			packet:      Packet{id: 5, magic: magic, flags: 8, length: 0, metadata: map[string]string{"K": strings.Repeat("V", maxSectionBytes)}},
+			err:         errSectionTooLarge,
+		},
+		{
+			packetBytes: []byte{0, 0, 0, 0, 0, 0, 0, 5, 0x77, 0x14, 0x30, 0xCB, 0, 0, 0, 0, 0, 0, 0, 8, 0, 0, 0, 3, 0, 14, 0, 1, 'A', 0, 1, '1', 0, 1, 'B', 0, 3, 'V', 'A', 'L', 'A', 'B', 'C'},
+			packet:      Packet{id: 5, magic: magic, flags: 8, length: 3, metadata: map[string]string{"B": "VAL", "A": "1"}, data: []byte("ABC")},
+			err:         nil,
+		},
+		{
+			packetBytes: []byte{0, 0, 0, 0, 0, 0, 0, 5, 0x77, 0x14, 0x30, 0xCB, 0, 0, 0, 0, 0, 0, 0, 0x10, 0, 0, 0, 3, 0, 0, 0, 0, 0x3B, 0x9A, 0xCA, 0, 'A', 'B', 'C'},
+			packet:      Packet{id: 5, magic: magic, flags: 0x10, length: 3, timeout: time.Second, data: []byte("ABC")},
+			err:         nil,
+		},
 	}
 
 	for _, testCase := range testCases {
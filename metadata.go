@@ -0,0 +1,37 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vex
+
+import "context"
+
+// Metadata is some key/value pairs carried in packet alongside the data, such as trace id and auth token.
+type Metadata map[string]string
+
+type metadataKey struct{}
+
+type responseMetadataKey struct{}
+
+// ContextWithMetadata returns a new context carrying the metadata which will be sent by client.
+// The metadata shouldn't be modified after calling this function.
+func ContextWithMetadata(ctx context.Context, metadata Metadata) context.Context {
+	return context.WithValue(ctx, metadataKey{}, metadata)
+}
+
+// MetadataFromContext returns the metadata carried by context.
+func MetadataFromContext(ctx context.Context) Metadata {
+	metadata, _ := ctx.Value(metadataKey{}).(Metadata)
+	return metadata
+}
+
+// ContextWithResponseMetadata returns a new context carrying the metadata which will be filled with
+// the metadata responded by server.
+func ContextWithResponseMetadata(ctx context.Context, metadata Metadata) context.Context {
+	return context.WithValue(ctx, responseMetadataKey{}, metadata)
+}
+
+func responseMetadataFromContext(ctx context.Context) Metadata {
+	metadata, _ := ctx.Value(responseMetadataKey{}).(Metadata)
+	return metadata
+}
@@ -0,0 +1,92 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vex
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// go test -v -cover -run=^TestContextWithMetadata$
+func TestContextWithMetadata(t *testing.T) {
+	ctx := context.Background()
+	if metadata := MetadataFromContext(ctx); metadata != nil {
+		t.Fatalf("got %+v != want nil", metadata)
+	}
+
+	metadata := Metadata{"key": "value"}
+	ctx = ContextWithMetadata(ctx, metadata)
+
+	got := fmt.Sprintf("%p", MetadataFromContext(ctx))
+	want := fmt.Sprintf("%p", metadata)
+	if got != want {
+		t.Fatalf("got %s != want %s", got, want)
+	}
+}
+
+// go test -v -cover -run=^TestContextWithResponseMetadata$
+func TestContextWithResponseMetadata(t *testing.T) {
+	ctx := context.Background()
+	if metadata := responseMetadataFromContext(ctx); metadata != nil {
+		t.Fatalf("got %+v != want nil", metadata)
+	}
+
+	metadata := Metadata{}
+	ctx = ContextWithResponseMetadata(ctx, metadata)
+
+	got := fmt.Sprintf("%p", responseMetadataFromContext(ctx))
+	want := fmt.Sprintf("%p", metadata)
+	if got != want {
+		t.Fatalf("got %s != want %s", got, want)
+	}
+}
+
+// go test -v -cover -run=^TestMetadata$
+func TestMetadata(t *testing.T) {
+	handler := HandlerFunc(func(ctx *Context, data []byte) ([]byte, error) {
+		metadata := ctx.Metadata()
+		ctx.SetResponseMetadata("trace_id", metadata["trace_id"])
+		ctx.SetResponseMetadata("tenant_id", metadata["tenant_id"])
+		return data, nil
+	})
+
+	svr := NewServer("127.0.0.1:0", handler)
+
+	go func() {
+		if err := svr.Serve(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	defer svr.Close()
+
+	time.Sleep(100 * time.Millisecond)
+	address := svr.(*server).listener.Addr().String()
+
+	client, err := NewClient(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	metadata := Metadata{"trace_id": "123", "tenant_id": "456"}
+	responseMetadata := Metadata{}
+
+	ctx := ContextWithMetadata(context.Background(), metadata)
+	ctx = ContextWithResponseMetadata(ctx, responseMetadata)
+
+	if _, err = client.Send(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	got := fmt.Sprintf("%+v", responseMetadata)
+	want := fmt.Sprintf("%+v", metadata)
+	if got != want {
+		t.Fatalf("got %s != want %s", got, want)
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vex
+
+import (
+	"io"
+	"time"
+)
+
+// Metrics records the metrics of server, and you can find some implementations in metrics package.
+// All methods should be safe for concurrent use and return quickly because they're called on the hot path.
+type Metrics interface {
+	// ConnAccepted is called when a connection is accepted.
+	ConnAccepted()
+
+	// ConnClosed is called when a connection is closed.
+	ConnClosed()
+
+	// RequestHandled is called when a request is handled with the error returned by handler.
+	// Use CodeOf to get the code of err if it isn't nil.
+	// The method will be empty if it isn't found so the methods sent by clients won't grow without bound.
+	RequestHandled(method string, err error, latency time.Duration)
+
+	// BytesRead is called when n bytes are read from a connection.
+	BytesRead(n int)
+
+	// BytesWritten is called when n bytes are written to a connection.
+	BytesWritten(n int)
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) ConnAccepted()                                                  {}
+func (nopMetrics) ConnClosed()                                                    {}
+func (nopMetrics) RequestHandled(method string, err error, latency time.Duration) {}
+func (nopMetrics) BytesRead(n int)                                                {}
+func (nopMetrics) BytesWritten(n int)                                             {}
+
+// countReader counts the bytes read from reader.
+type countReader struct {
+	reader io.Reader
+	count  func(n int)
+}
+
+func (cr countReader) Read(p []byte) (n int, err error) {
+	n, err = cr.reader.Read(p)
+	if n > 0 {
+		cr.count(n)
+	}
+
+	return n, err
+}
+
+// countWriter counts the bytes written to writer.
+type countWriter struct {
+	writer io.Writer
+	count  func(n int)
+}
+
+func (cw countWriter) Write(p []byte) (n int, err error) {
+	n, err = cw.writer.Write(p)
+	if n > 0 {
+		cw.count(n)
+	}
+
+	return n, err
+}
@@ -0,0 +1,115 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package metrics
+
+import (
+	"encoding/json"
+	"expvar"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/FishGoddess/vex"
+)
+
+type histogramVar struct {
+	*histogram
+}
+
+// String returns the json of histogram snapshot so it can be used as an expvar.Var.
+func (hv histogramVar) String() string {
+	marshaled, err := json.Marshal(hv.snapshot())
+	if err != nil {
+		return "{}"
+	}
+
+	return string(marshaled)
+}
+
+// Expvar records the metrics of server in expvar, so they can be found in /debug/vars.
+type Expvar struct {
+	buckets []float64
+
+	connsActive   *expvar.Int
+	connsAccepted *expvar.Int
+	connsClosed   *expvar.Int
+	bytesRead     *expvar.Int
+	bytesWritten  *expvar.Int
+	requests      *expvar.Map
+	errors        *expvar.Map
+	latencies     *expvar.Map
+
+	lock sync.Mutex
+}
+
+// NewExpvar returns an expvar metrics published with name and the buckets of latency histograms in seconds.
+// DefaultBuckets will be used if no buckets are given.
+// It panics if the name is already published like expvar.Publish.
+func NewExpvar(name string, buckets ...float64) *Expvar {
+	e := &Expvar{
+		buckets:       sortedBuckets(buckets),
+		connsActive:   new(expvar.Int),
+		connsAccepted: new(expvar.Int),
+		connsClosed:   new(expvar.Int),
+		bytesRead:     new(expvar.Int),
+		bytesWritten:  new(expvar.Int),
+		requests:      new(expvar.Map),
+		errors:        new(expvar.Map),
+		latencies:     new(expvar.Map),
+	}
+
+	vars := expvar.NewMap(name)
+	vars.Set("conns_active", e.connsActive)
+	vars.Set("conns_accepted", e.connsAccepted)
+	vars.Set("conns_closed", e.connsClosed)
+	vars.Set("requests", e.requests)
+	vars.Set("errors", e.errors)
+	vars.Set("latencies", e.latencies)
+	vars.Set("bytes_read", e.bytesRead)
+	vars.Set("bytes_written", e.bytesWritten)
+	return e
+}
+
+// ConnAccepted is called when a connection is accepted.
+func (e *Expvar) ConnAccepted() {
+	e.connsActive.Add(1)
+	e.connsAccepted.Add(1)
+}
+
+// ConnClosed is called when a connection is closed.
+func (e *Expvar) ConnClosed() {
+	e.connsActive.Add(-1)
+	e.connsClosed.Add(1)
+}
+
+// RequestHandled is called when a request is handled with the error returned by handler.
+func (e *Expvar) RequestHandled(method string, err error, latency time.Duration) {
+	e.requests.Add(method, 1)
+
+	if err != nil {
+		code := strconv.FormatUint(uint64(vex.CodeOf(err)), 10)
+		e.errors.Add(code, 1)
+	}
+
+	e.lock.Lock()
+	hv, ok := e.latencies.Get(method).(histogramVar)
+	if !ok {
+		hv = histogramVar{histogram: newHistogram(e.buckets)}
+		e.latencies.Set(method, hv)
+	}
+	e.lock.Unlock()
+
+	hv.observe(latency)
+}
+
+// BytesRead is called when n bytes are read from a connection.
+func (e *Expvar) BytesRead(n int) {
+	e.bytesRead.Add(int64(n))
+}
+
+// BytesWritten is called when n bytes are written to a connection.
+func (e *Expvar) BytesWritten(n int) {
+	e.bytesWritten.Add(int64(n))
+}
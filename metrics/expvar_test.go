@@ -0,0 +1,70 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package metrics
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/FishGoddess/vex"
+)
+
+// expvarRuns makes the names of expvar unique because they can't be published twice, like running with -count=2.
+var expvarRuns atomic.Int64
+
+// go test -v -cover -run=^TestExpvar$
+func TestExpvar(t *testing.T) {
+	name := fmt.Sprintf("%s_%d", t.Name(), expvarRuns.Add(1))
+
+	var metrics vex.Metrics = NewExpvar(name, 0.1, 1)
+	metrics.ConnAccepted()
+	metrics.ConnAccepted()
+	metrics.ConnClosed()
+	metrics.RequestHandled("hello", nil, 50*time.Millisecond)
+	metrics.RequestHandled("hello", vex.ErrNotFound, 500*time.Millisecond)
+	metrics.BytesRead(64)
+	metrics.BytesWritten(32)
+
+	type vars struct {
+		ConnsActive   int64                        `json:"conns_active"`
+		ConnsAccepted int64                        `json:"conns_accepted"`
+		ConnsClosed   int64                        `json:"conns_closed"`
+		Requests      map[string]int64             `json:"requests"`
+		Errors        map[string]int64             `json:"errors"`
+		Latencies     map[string]HistogramSnapshot `json:"latencies"`
+		BytesRead     int64                        `json:"bytes_read"`
+		BytesWritten  int64                        `json:"bytes_written"`
+	}
+
+	var got vars
+	if err := json.Unmarshal([]byte(expvar.Get(name).String()), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.ConnsActive != 1 || got.ConnsAccepted != 2 || got.ConnsClosed != 1 {
+		t.Fatalf("got conns (%d, %d, %d) != want (1, 2, 1)", got.ConnsActive, got.ConnsAccepted, got.ConnsClosed)
+	}
+
+	if got.Requests["hello"] != 2 {
+		t.Fatalf("got %d != want 2", got.Requests["hello"])
+	}
+
+	if got.Errors["404"] != 1 {
+		t.Fatalf("got %d != want 1", got.Errors["404"])
+	}
+
+	latency := got.Latencies["hello"]
+	if latency.Count != 2 || latency.Counts[0] != 1 || latency.Counts[1] != 2 {
+		t.Fatalf("got %+v is wrong", latency)
+	}
+
+	if got.BytesRead != 64 || got.BytesWritten != 32 {
+		t.Fatalf("got bytes (%d, %d) != want (64, 32)", got.BytesRead, got.BytesWritten)
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package metrics
+
+import (
+	"slices"
+	"time"
+
+	histograms "github.com/FishGoddess/vex/internal/histogram"
+)
+
+// DefaultBuckets are the upper bounds of latency buckets in seconds.
+var DefaultBuckets = []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5}
+
+// HistogramSnapshot is the snapshot of a latency histogram.
+type HistogramSnapshot struct {
+	// Buckets are the upper bounds of buckets in seconds.
+	Buckets []float64 `json:"buckets"`
+
+	// Counts are the cumulative counts of buckets, which means Counts[i] is the count of latencies <= Buckets[i].
+	Counts []uint64 `json:"counts"`
+
+	// Count is the count of all latencies and Sum is the sum of them in seconds.
+	Count uint64  `json:"count"`
+	Sum   float64 `json:"sum"`
+}
+
+// histogram records latencies in seconds.
+type histogram struct {
+	seconds *histograms.Histogram[float64]
+}
+
+func newHistogram(buckets []float64) *histogram {
+	h := &histogram{
+		seconds: histograms.New(buckets),
+	}
+
+	return h
+}
+
+func (h *histogram) observe(latency time.Duration) {
+	h.seconds.Observe(latency.Seconds())
+}
+
+func (h *histogram) snapshot() HistogramSnapshot {
+	snapshot := h.seconds.Snapshot()
+	return HistogramSnapshot(snapshot)
+}
+
+// sortedBuckets returns a sorted copy of buckets or DefaultBuckets if buckets is empty.
+func sortedBuckets(buckets []float64) []float64 {
+	if len(buckets) == 0 {
+		return DefaultBuckets
+	}
+
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+	return slices.Compact(buckets)
+}
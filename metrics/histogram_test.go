@@ -0,0 +1,50 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package metrics
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// go test -v -cover -run=^TestHistogram$
+func TestHistogram(t *testing.T) {
+	h := newHistogram([]float64{0.1, 1})
+	h.observe(50 * time.Millisecond)
+	h.observe(100 * time.Millisecond)
+	h.observe(500 * time.Millisecond)
+	h.observe(2 * time.Second)
+
+	snapshot := h.snapshot()
+
+	want := []uint64{2, 3}
+	if !slices.Equal(snapshot.Counts, want) {
+		t.Fatalf("got %+v != want %+v", snapshot.Counts, want)
+	}
+
+	if snapshot.Count != 4 {
+		t.Fatalf("got %d != want 4", snapshot.Count)
+	}
+
+	if snapshot.Sum != 2.65 {
+		t.Fatalf("got %f != want 2.65", snapshot.Sum)
+	}
+}
+
+// go test -v -cover -run=^TestSortedBuckets$
+func TestSortedBuckets(t *testing.T) {
+	buckets := sortedBuckets(nil)
+	if !slices.Equal(buckets, DefaultBuckets) {
+		t.Fatalf("got %+v != want %+v", buckets, DefaultBuckets)
+	}
+
+	buckets = sortedBuckets([]float64{1, 0.1, 1, 0.5})
+
+	want := []float64{0.1, 0.5, 1}
+	if !slices.Equal(buckets, want) {
+		t.Fatalf("got %+v != want %+v", buckets, want)
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/FishGoddess/vex"
+)
+
+var labelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Prometheus records the metrics of server and exports them in prometheus text format.
+// It implements http.Handler so you can register it to your metrics endpoint.
+type Prometheus struct {
+	buckets []float64
+
+	connsActive   atomic.Int64
+	connsAccepted atomic.Uint64
+	connsClosed   atomic.Uint64
+	bytesRead     atomic.Uint64
+	bytesWritten  atomic.Uint64
+
+	requests  map[string]uint64
+	errors    map[vex.Code]uint64
+	latencies map[string]*histogram
+
+	lock sync.Mutex
+}
+
+// NewPrometheus returns a prometheus metrics with the buckets of latency histograms in seconds.
+// DefaultBuckets will be used if no buckets are given.
+func NewPrometheus(buckets ...float64) *Prometheus {
+	p := &Prometheus{
+		buckets:   sortedBuckets(buckets),
+		requests:  make(map[string]uint64, 16),
+		errors:    make(map[vex.Code]uint64, 16),
+		latencies: make(map[string]*histogram, 16),
+	}
+
+	return p
+}
+
+// ConnAccepted is called when a connection is accepted.
+func (p *Prometheus) ConnAccepted() {
+	p.connsActive.Add(1)
+	p.connsAccepted.Add(1)
+}
+
+// ConnClosed is called when a connection is closed.
+func (p *Prometheus) ConnClosed() {
+	p.connsActive.Add(-1)
+	p.connsClosed.Add(1)
+}
+
+// RequestHandled is called when a request is handled with the error returned by handler.
+func (p *Prometheus) RequestHandled(method string, err error, latency time.Duration) {
+	p.lock.Lock()
+	p.requests[method]++
+
+	if err != nil {
+		p.errors[vex.CodeOf(err)]++
+	}
+
+	h := p.latencies[method]
+	if h == nil {
+		h = newHistogram(p.buckets)
+		p.latencies[method] = h
+	}
+	p.lock.Unlock()
+
+	h.observe(latency)
+}
+
+// BytesRead is called when n bytes are read from a connection.
+func (p *Prometheus) BytesRead(n int) {
+	p.bytesRead.Add(uint64(n))
+}
+
+// BytesWritten is called when n bytes are written to a connection.
+func (p *Prometheus) BytesWritten(n int) {
+	p.bytesWritten.Add(uint64(n))
+}
+
+func writeMetric(buffer *bytes.Buffer, name string, kind string, help string) {
+	fmt.Fprintf(buffer, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(buffer, "# TYPE %s %s\n", name, kind)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// WriteTo writes the metrics in prometheus text format to writer.
+func (p *Prometheus) WriteTo(writer io.Writer) (int64, error) {
+	p.lock.Lock()
+	requests := maps.Clone(p.requests)
+	errors := maps.Clone(p.errors)
+	latencies := make(map[string]HistogramSnapshot, len(p.latencies))
+	for method, h := range p.latencies {
+		latencies[method] = h.snapshot()
+	}
+	p.lock.Unlock()
+
+	var buffer bytes.Buffer
+	writeMetric(&buffer, "vex_conns_active", "gauge", "The number of active connections.")
+	fmt.Fprintf(&buffer, "vex_conns_active %d\n", p.connsActive.Load())
+
+	writeMetric(&buffer, "vex_conns_accepted_total", "counter", "The number of accepted connections.")
+	fmt.Fprintf(&buffer, "vex_conns_accepted_total %d\n", p.connsAccepted.Load())
+
+	writeMetric(&buffer, "vex_conns_closed_total", "counter", "The number of closed connections.")
+	fmt.Fprintf(&buffer, "vex_conns_closed_total %d\n", p.connsClosed.Load())
+
+	writeMetric(&buffer, "vex_requests_total", "counter", "The number of handled requests by method.")
+	for _, method := range slices.Sorted(maps.Keys(requests)) {
+		fmt.Fprintf(&buffer, "vex_requests_total{method=\"%s\"} %d\n", labelReplacer.Replace(method), requests[method])
+	}
+
+	writeMetric(&buffer, "vex_errors_total", "counter", "The number of failed requests by error code.")
+	for _, code := range slices.Sorted(maps.Keys(errors)) {
+		fmt.Fprintf(&buffer, "vex_errors_total{code=\"%d\"} %d\n", code, errors[code])
+	}
+
+	writeMetric(&buffer, "vex_request_duration_seconds", "histogram", "The latency of handling requests by method.")
+	for _, method := range slices.Sorted(maps.Keys(latencies)) {
+		snapshot := latencies[method]
+		label := labelReplacer.Replace(method)
+
+		for i, bucket := range snapshot.Buckets {
+			fmt.Fprintf(&buffer, "vex_request_duration_seconds_bucket{method=\"%s\",le=\"%s\"} %d\n", label, formatFloat(bucket), snapshot.Counts[i])
+		}
+
+		fmt.Fprintf(&buffer, "vex_request_duration_seconds_bucket{method=\"%s\",le=\"+Inf\"} %d\n", label, snapshot.Count)
+		fmt.Fprintf(&buffer, "vex_request_duration_seconds_sum{method=\"%s\"} %s\n", label, formatFloat(snapshot.Sum))
+		fmt.Fprintf(&buffer, "vex_request_duration_seconds_count{method=\"%s\"} %d\n", label, snapshot.Count)
+	}
+
+	writeMetric(&buffer, "vex_bytes_read_total", "counter", "The number of bytes read from connections.")
+	fmt.Fprintf(&buffer, "vex_bytes_read_total %d\n", p.bytesRead.Load())
+
+	writeMetric(&buffer, "vex_bytes_written_total", "counter", "The number of bytes written to connections.")
+	fmt.Fprintf(&buffer, "vex_bytes_written_total %d\n", p.bytesWritten.Load())
+
+	return buffer.WriteTo(writer)
+}
+
+// ServeHTTP writes the metrics in prometheus text format to response.
+func (p *Prometheus) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	writer.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	p.WriteTo(writer)
+}
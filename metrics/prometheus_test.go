@@ -0,0 +1,84 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FishGoddess/vex"
+)
+
+// go test -v -cover -run=^TestPrometheus$
+func TestPrometheus(t *testing.T) {
+	var metrics vex.Metrics = NewPrometheus(0.1, 1)
+	metrics.ConnAccepted()
+	metrics.ConnAccepted()
+	metrics.ConnClosed()
+	metrics.RequestHandled("hello", nil, 50*time.Millisecond)
+	metrics.RequestHandled("hello", vex.ErrNotFound, 500*time.Millisecond)
+	metrics.RequestHandled("say \"hi\"", vex.ErrInternal, 2*time.Second)
+	metrics.BytesRead(64)
+	metrics.BytesWritten(32)
+
+	want := `# HELP vex_conns_active The number of active connections.
+# TYPE vex_conns_active gauge
+vex_conns_active 1
+# HELP vex_conns_accepted_total The number of accepted connections.
+# TYPE vex_conns_accepted_total counter
+vex_conns_accepted_total 2
+# HELP vex_conns_closed_total The number of closed connections.
+# TYPE vex_conns_closed_total counter
+vex_conns_closed_total 1
+# HELP vex_requests_total The number of handled requests by method.
+# TYPE vex_requests_total counter
+vex_requests_total{method="hello"} 2
+vex_requests_total{method="say \"hi\""} 1
+# HELP vex_errors_total The number of failed requests by error code.
+# TYPE vex_errors_total counter
+vex_errors_total{code="404"} 1
+vex_errors_total{code="500"} 1
+# HELP vex_request_duration_seconds The latency of handling requests by method.
+# TYPE vex_request_duration_seconds histogram
+vex_request_duration_seconds_bucket{method="hello",le="0.1"} 1
+vex_request_duration_seconds_bucket{method="hello",le="1"} 2
+vex_request_duration_seconds_bucket{method="hello",le="+Inf"} 2
+vex_request_duration_seconds_sum{method="hello"} 0.55
+vex_request_duration_seconds_count{method="hello"} 2
+vex_request_duration_seconds_bucket{method="say \"hi\"",le="0.1"} 0
+vex_request_duration_seconds_bucket{method="say \"hi\"",le="1"} 0
+vex_request_duration_seconds_bucket{method="say \"hi\"",le="+Inf"} 1
+vex_request_duration_seconds_sum{method="say \"hi\""} 2
+vex_request_duration_seconds_count{method="say \"hi\""} 1
+# HELP vex_bytes_read_total The number of bytes read from connections.
+# TYPE vex_bytes_read_total counter
+vex_bytes_read_total 64
+# HELP vex_bytes_written_total The number of bytes written to connections.
+# TYPE vex_bytes_written_total counter
+vex_bytes_written_total 32
+`
+
+	var builder strings.Builder
+	if _, err := metrics.(*Prometheus).WriteTo(&builder); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := builder.String(); got != want {
+		t.Fatalf("got %s != want %s", got, want)
+	}
+
+	recorder := httptest.NewRecorder()
+	metrics.(*Prometheus).ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	if got := recorder.Body.String(); got != want {
+		t.Fatalf("got %s != want %s", got, want)
+	}
+
+	if contentType := recorder.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain") {
+		t.Fatalf("got %s is wrong", contentType)
+	}
+}
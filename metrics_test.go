@@ -0,0 +1,115 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vex
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testMetrics struct {
+	connsAccepted atomic.Int64
+	connsClosed   atomic.Int64
+	bytesRead     atomic.Int64
+	bytesWritten  atomic.Int64
+
+	requests map[string]int
+	errors   map[Code]int
+	lock     sync.Mutex
+}
+
+func (tm *testMetrics) ConnAccepted() {
+	tm.connsAccepted.Add(1)
+}
+
+func (tm *testMetrics) ConnClosed() {
+	tm.connsClosed.Add(1)
+}
+
+func (tm *testMetrics) RequestHandled(method string, err error, latency time.Duration) {
+	tm.lock.Lock()
+	defer tm.lock.Unlock()
+
+	tm.requests[method]++
+
+	if err != nil {
+		tm.errors[CodeOf(err)]++
+	}
+}
+
+func (tm *testMetrics) BytesRead(n int) {
+	tm.bytesRead.Add(int64(n))
+}
+
+func (tm *testMetrics) BytesWritten(n int) {
+	tm.bytesWritten.Add(int64(n))
+}
+
+// go test -v -cover -run=^TestServerMetrics$
+func TestServerMetrics(t *testing.T) {
+	metrics := &testMetrics{requests: map[string]int{}, errors: map[Code]int{}}
+
+	router := NewRouter()
+	router.RegisterFunc("hello", func(ctx *Context, data []byte) ([]byte, error) {
+		return data, nil
+	})
+
+	svr := NewServer("127.0.0.1:0", router, WithMetrics(metrics))
+
+	go func() {
+		if err := svr.Serve(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	defer svr.Close()
+
+	time.Sleep(100 * time.Millisecond)
+	address := svr.(*server).listener.Addr().String()
+
+	client, err := NewClient(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := ContextWithMethod(context.Background(), "hello")
+	if _, err = client.Send(ctx, []byte("world")); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx = ContextWithMethod(context.Background(), "missing")
+	if _, err = client.Send(ctx, nil); !errors.Is(err, ErrMethodNotFound) {
+		t.Fatalf("got %+v != want %+v", err, ErrMethodNotFound)
+	}
+
+	if err = client.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if metrics.connsAccepted.Load() != 1 || metrics.connsClosed.Load() != 1 {
+		t.Fatalf("got conns (%d, %d) != want (1, 1)", metrics.connsAccepted.Load(), metrics.connsClosed.Load())
+	}
+
+	metrics.lock.Lock()
+	defer metrics.lock.Unlock()
+
+	if metrics.requests["hello"] != 1 || metrics.requests[""] != 1 || len(metrics.requests) != 2 {
+		t.Fatalf("got %+v is wrong", metrics.requests)
+	}
+
+	if metrics.errors[CodeMethodNotFound] != 1 || len(metrics.errors) != 1 {
+		t.Fatalf("got %+v is wrong", metrics.errors)
+	}
+
+	if metrics.bytesRead.Load() <= 0 || metrics.bytesWritten.Load() <= 0 {
+		t.Fatalf("got bytes (%d, %d) is wrong", metrics.bytesRead.Load(), metrics.bytesWritten.Load())
+	}
+}
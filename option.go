@@ -5,7 +5,10 @@
 package vex
 
 import (
+	"crypto/tls"
 	"log/slog"
+	"os"
+	"syscall"
 	"time"
 )
 
@@ -17,15 +20,67 @@ type Logger interface {
 	Error(msg string, kvs ...any)
 }
 
+// PanicHandler handles the panic recovered from handler and returns an error which will be sent to client.
+type PanicHandler func(ctx *Context, recovered any) error
+
+// SignalFunc is called when the server receives a signal.
+type SignalFunc func(signal os.Signal)
+
+// ReloadFunc is called when the server receives SIGHUP and returns an error if failed.
+type ReloadFunc func() error
+
+// ConnLimitPolicy decides what the server does when the number of connections reaches the limit.
+type ConnLimitPolicy uint8
+
+const (
+	// ConnLimitReject accepts and rejects the new connection with an error packet immediately.
+	ConnLimitReject ConnLimitPolicy = iota
+
+	// ConnLimitBlock stops accepting new connections until some connections are closed.
+	ConnLimitBlock
+)
+
 type config struct {
-	logger      Logger
-	dialTimeout time.Duration
+	logger          Logger
+	dialTimeout     time.Duration
+	connConcurrency uint64
+	maxConns        uint64
+	maxConnsPerIP   uint64
+	connLimitPolicy ConnLimitPolicy
+	transport       Transport
+	tlsConfig       *tls.Config
+
+	serverInterceptors []ServerInterceptor
+	clientInterceptors []ClientInterceptor
+	panicHandler       PanicHandler
+	metrics            Metrics
+
+	streamWindow uint32
+
+	heartbeatInterval time.Duration
+	heartbeatTimeout  time.Duration
+
+	reconnect         bool
+	reconnectMinDelay time.Duration
+	reconnectMaxDelay time.Duration
+	stateFunc         StateFunc
+
+	signals    []os.Signal
+	signalFunc SignalFunc
+	reloadFunc ReloadFunc
 }
 
 func newConfig() *config {
 	conf := &config{
 		logger:      slog.Default(),
 		dialTimeout: 3 * time.Second,
+		transport:   NewTCPTransport(),
+		metrics:     nopMetrics{},
+
+		streamWindow: streamWindowBytes,
+
+		reconnectMinDelay: 100 * time.Millisecond,
+		reconnectMaxDelay: 10 * time.Second,
 	}
 
 	return conf
@@ -55,3 +110,147 @@ func WithDialTimeout(timeout time.Duration) Option {
 		c.dialTimeout = timeout
 	}
 }
+
+// WithConnConcurrency sets the max number of requests handled concurrently in one connection to config.
+// Streams aren't limited by it because they may last for a long time.
+// Zero means no limit.
+func WithConnConcurrency(concurrency uint64) Option {
+	return func(c *config) {
+		c.connConcurrency = concurrency
+	}
+}
+
+// WithServerInterceptors appends the interceptors of server to config.
+// The interceptors will be called in order.
+func WithServerInterceptors(interceptors ...ServerInterceptor) Option {
+	return func(c *config) {
+		c.serverInterceptors = append(c.serverInterceptors, interceptors...)
+	}
+}
+
+// WithClientInterceptors appends the interceptors of client to config.
+// The interceptors will be called in order.
+func WithClientInterceptors(interceptors ...ClientInterceptor) Option {
+	return func(c *config) {
+		c.clientInterceptors = append(c.clientInterceptors, interceptors...)
+	}
+}
+
+// WithMaxConns sets the max number of connections and the policy used when reaching the limit to config.
+// Zero means no limit.
+func WithMaxConns(maxConns uint64, policy ConnLimitPolicy) Option {
+	return func(c *config) {
+		c.maxConns = maxConns
+		c.connLimitPolicy = policy
+	}
+}
+
+// WithMaxConnsPerIP sets the max number of connections from one remote ip to config.
+// The connection will be rejected with an error packet if its ip reaches the limit.
+// Zero means no limit.
+func WithMaxConnsPerIP(maxConns uint64) Option {
+	return func(c *config) {
+		c.maxConnsPerIP = maxConns
+	}
+}
+
+// WithPanicHandler sets the panic handler to config.
+// The default one logs the panic with stack and returns an internal error.
+func WithPanicHandler(handler PanicHandler) Option {
+	return func(c *config) {
+		c.panicHandler = handler
+	}
+}
+
+// WithMetrics sets the metrics to config which records the connections, requests and bytes of server.
+func WithMetrics(metrics Metrics) Option {
+	return func(c *config) {
+		c.metrics = metrics
+	}
+}
+
+// WithStreamWindow sets the flow control window of receiving data from a stream in bytes to config.
+// The sender of stream will be blocked if the data not received reaches the window.
+// The window smaller than 64KB will be ignored since it's the initial window of streams.
+func WithStreamWindow(window uint32) Option {
+	return func(c *config) {
+		c.streamWindow = max(window, streamWindowBytes)
+	}
+}
+
+// WithHeartbeat sets the heartbeat interval and timeout to config.
+// A ping packet will be sent every interval, and the connection will be closed if nothing is received within timeout.
+// The timeout will be three times the interval if it isn't greater than the interval.
+// Zero interval means disabling heartbeat.
+func WithHeartbeat(interval time.Duration, timeout time.Duration) Option {
+	return func(c *config) {
+		if timeout <= interval {
+			timeout = 3 * interval
+		}
+
+		c.heartbeatInterval = interval
+		c.heartbeatTimeout = timeout
+	}
+}
+
+// WithReconnect enables the reconnection of client with the min and max delays of exponential backoff.
+// The client will redial the address with jitter delays when its connection is lost.
+func WithReconnect(minDelay time.Duration, maxDelay time.Duration) Option {
+	return func(c *config) {
+		c.reconnect = true
+		c.reconnectMinDelay = minDelay
+		c.reconnectMaxDelay = maxDelay
+	}
+}
+
+// WithStateFunc sets the function called when the state of reconnecting client changes to config.
+func WithStateFunc(stateFunc StateFunc) Option {
+	return func(c *config) {
+		c.stateFunc = stateFunc
+	}
+}
+
+// WithTransport sets the transport to config which decides the network used by client and server.
+func WithTransport(transport Transport) Option {
+	return func(c *config) {
+		c.transport = transport
+	}
+}
+
+// WithTLSConfig sets the tls config to config so the traffic will be encrypted.
+// Set ClientAuth and ClientCAs of tls config in server to verify the certificates of clients,
+// and set Certificates of tls config in client to send its certificate.
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(c *config) {
+		c.tlsConfig = tlsConfig
+	}
+}
+
+// WithSignals enables the signal handling of server and the server will be closed when receiving one of signals.
+// SIGHUP, SIGINT, SIGQUIT and SIGTERM will be used if no signals are given.
+// Server won't handle any signals without this option so you can manage the lifecycle by yourself.
+func WithSignals(signals ...os.Signal) Option {
+	return func(c *config) {
+		if len(signals) == 0 {
+			signals = []os.Signal{syscall.SIGHUP, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGTERM}
+		}
+
+		c.signals = signals
+	}
+}
+
+// WithSignalFunc sets the function called when the server receives a signal to config.
+// It only works with WithSignals.
+func WithSignalFunc(signalFunc SignalFunc) Option {
+	return func(c *config) {
+		c.signalFunc = signalFunc
+	}
+}
+
+// WithReloadFunc sets the reload function to config so SIGHUP will reload instead of closing the server.
+// It only works with WithSignals, and SIGHUP will be handled even if it's not one of the signals.
+func WithReloadFunc(reloadFunc ReloadFunc) Option {
+	return func(c *config) {
+		c.reloadFunc = reloadFunc
+	}
+}
@@ -5,9 +5,13 @@
 package vex
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
 	"log/slog"
 	"os"
+	"slices"
+	"syscall"
 	"testing"
 	"time"
 )
@@ -20,8 +24,8 @@ func TestConfigApply(t *testing.T) {
 	opt1 := func(c *config) { c.logger = logger }
 	opt2 := func(c *config) { c.dialTimeout = 2 }
 
-	got := *conf.apply(opt1, opt2)
-	want := config{logger: logger, dialTimeout: 2}
+	got := fmt.Sprintf("%+v", *conf.apply(opt1, opt2))
+	want := fmt.Sprintf("%+v", config{logger: logger, dialTimeout: 2})
 	if got != want {
 		t.Fatalf("got %+v != want %+v", got, want)
 	}
@@ -54,3 +58,249 @@ func TestWithDialTimeout(t *testing.T) {
 		t.Fatalf("got %d != want %d", got, want)
 	}
 }
+
+// go test -v -cover -run=^TestWithConnConcurrency$
+func TestWithConnConcurrency(t *testing.T) {
+	concurrency := uint64(16)
+
+	conf := &config{connConcurrency: 0}
+	WithConnConcurrency(concurrency)(conf)
+
+	got := conf.connConcurrency
+	want := concurrency
+	if got != want {
+		t.Fatalf("got %d != want %d", got, want)
+	}
+}
+
+// go test -v -cover -run=^TestWithServerInterceptors$
+func TestWithServerInterceptors(t *testing.T) {
+	interceptor := func(ctx *Context, data []byte, handler Handler) ([]byte, error) {
+		return handler.Handle(ctx, data)
+	}
+
+	conf := &config{serverInterceptors: nil}
+	WithServerInterceptors(interceptor, interceptor)(conf)
+	WithServerInterceptors(interceptor)(conf)
+
+	got := len(conf.serverInterceptors)
+	want := 3
+	if got != want {
+		t.Fatalf("got %d != want %d", got, want)
+	}
+}
+
+// go test -v -cover -run=^TestWithClientInterceptors$
+func TestWithClientInterceptors(t *testing.T) {
+	interceptor := func(ctx context.Context, data []byte, send SendFunc) ([]byte, error) {
+		return send(ctx, data)
+	}
+
+	conf := &config{clientInterceptors: nil}
+	WithClientInterceptors(interceptor, interceptor)(conf)
+	WithClientInterceptors(interceptor)(conf)
+
+	got := len(conf.clientInterceptors)
+	want := 3
+	if got != want {
+		t.Fatalf("got %d != want %d", got, want)
+	}
+}
+
+// go test -v -cover -run=^TestWithPanicHandler$
+func TestWithPanicHandler(t *testing.T) {
+	handler := func(ctx *Context, recovered any) error {
+		return nil
+	}
+
+	conf := &config{panicHandler: nil}
+	WithPanicHandler(handler)(conf)
+
+	got := fmt.Sprintf("%p", conf.panicHandler)
+	want := fmt.Sprintf("%p", handler)
+	if got != want {
+		t.Fatalf("got %s != want %s", got, want)
+	}
+}
+
+// go test -v -cover -run=^TestWithHeartbeat$
+func TestWithHeartbeat(t *testing.T) {
+	interval := time.Second
+	timeout := 3 * time.Second
+
+	conf := &config{heartbeatInterval: 0, heartbeatTimeout: 0}
+	WithHeartbeat(interval, timeout)(conf)
+
+	if conf.heartbeatInterval != interval {
+		t.Fatalf("got %d != want %d", conf.heartbeatInterval, interval)
+	}
+
+	if conf.heartbeatTimeout != timeout {
+		t.Fatalf("got %d != want %d", conf.heartbeatTimeout, timeout)
+	}
+
+	for _, timeout := range []time.Duration{0, interval / 2, interval} {
+		WithHeartbeat(interval, timeout)(conf)
+
+		if conf.heartbeatTimeout != 3*interval {
+			t.Fatalf("got %d != want %d", conf.heartbeatTimeout, 3*interval)
+		}
+	}
+}
+
+// go test -v -cover -run=^TestWithReconnect$
+func TestWithReconnect(t *testing.T) {
+	minDelay := time.Second
+	maxDelay := time.Minute
+
+	conf := &config{reconnect: false}
+	WithReconnect(minDelay, maxDelay)(conf)
+
+	if !conf.reconnect {
+		t.Fatal("conf.reconnect is false")
+	}
+
+	if conf.reconnectMinDelay != minDelay {
+		t.Fatalf("got %d != want %d", conf.reconnectMinDelay, minDelay)
+	}
+
+	if conf.reconnectMaxDelay != maxDelay {
+		t.Fatalf("got %d != want %d", conf.reconnectMaxDelay, maxDelay)
+	}
+}
+
+// go test -v -cover -run=^TestWithStateFunc$
+func TestWithStateFunc(t *testing.T) {
+	stateFunc := func(state State, err error) {}
+
+	conf := &config{stateFunc: nil}
+	WithStateFunc(stateFunc)(conf)
+
+	got := fmt.Sprintf("%p", conf.stateFunc)
+	want := fmt.Sprintf("%p", stateFunc)
+	if got != want {
+		t.Fatalf("got %s != want %s", got, want)
+	}
+}
+
+// go test -v -cover -run=^TestWithTLSConfig$
+func TestWithTLSConfig(t *testing.T) {
+	tlsConfig := &tls.Config{ServerName: "vex"}
+
+	conf := &config{tlsConfig: nil}
+	WithTLSConfig(tlsConfig)(conf)
+
+	if conf.tlsConfig != tlsConfig {
+		t.Fatalf("got %p != want %p", conf.tlsConfig, tlsConfig)
+	}
+}
+
+// go test -v -cover -run=^TestWithTransport$
+func TestWithTransport(t *testing.T) {
+	transport := NewPipeTransport()
+
+	conf := &config{transport: nil}
+	WithTransport(transport)(conf)
+
+	if conf.transport != transport {
+		t.Fatalf("got %p != want %p", conf.transport, transport)
+	}
+}
+
+// go test -v -cover -run=^TestWithSignals$
+func TestWithSignals(t *testing.T) {
+	conf := &config{signals: nil}
+	WithSignals()(conf)
+
+	want := []os.Signal{syscall.SIGHUP, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGTERM}
+	if !slices.Equal(conf.signals, want) {
+		t.Fatalf("got %+v != want %+v", conf.signals, want)
+	}
+
+	WithSignals(syscall.SIGTERM)(conf)
+
+	want = []os.Signal{syscall.SIGTERM}
+	if !slices.Equal(conf.signals, want) {
+		t.Fatalf("got %+v != want %+v", conf.signals, want)
+	}
+}
+
+// go test -v -cover -run=^TestWithSignalFunc$
+func TestWithSignalFunc(t *testing.T) {
+	signalFunc := func(signal os.Signal) {}
+
+	conf := &config{signalFunc: nil}
+	WithSignalFunc(signalFunc)(conf)
+
+	got := fmt.Sprintf("%p", conf.signalFunc)
+	want := fmt.Sprintf("%p", signalFunc)
+	if got != want {
+		t.Fatalf("got %s != want %s", got, want)
+	}
+}
+
+// go test -v -cover -run=^TestWithReloadFunc$
+func TestWithReloadFunc(t *testing.T) {
+	reloadFunc := func() error { return nil }
+
+	conf := &config{reloadFunc: nil}
+	WithReloadFunc(reloadFunc)(conf)
+
+	got := fmt.Sprintf("%p", conf.reloadFunc)
+	want := fmt.Sprintf("%p", reloadFunc)
+	if got != want {
+		t.Fatalf("got %s != want %s", got, want)
+	}
+}
+
+// go test -v -cover -run=^TestWithMaxConns$
+func TestWithMaxConns(t *testing.T) {
+	conf := &config{maxConns: 0, connLimitPolicy: ConnLimitReject}
+	WithMaxConns(16, ConnLimitBlock)(conf)
+
+	if conf.maxConns != 16 {
+		t.Fatalf("got %d != want 16", conf.maxConns)
+	}
+
+	if conf.connLimitPolicy != ConnLimitBlock {
+		t.Fatalf("got %d != want %d", conf.connLimitPolicy, ConnLimitBlock)
+	}
+}
+
+// go test -v -cover -run=^TestWithMaxConnsPerIP$
+func TestWithMaxConnsPerIP(t *testing.T) {
+	conf := &config{maxConnsPerIP: 0}
+	WithMaxConnsPerIP(4)(conf)
+
+	if conf.maxConnsPerIP != 4 {
+		t.Fatalf("got %d != want 4", conf.maxConnsPerIP)
+	}
+}
+
+// go test -v -cover -run=^TestWithMetrics$
+func TestWithMetrics(t *testing.T) {
+	metrics := new(testMetrics)
+
+	conf := &config{metrics: nil}
+	WithMetrics(metrics)(conf)
+
+	if conf.metrics != metrics {
+		t.Fatalf("got %p != want %p", conf.metrics, metrics)
+	}
+}
+
+// go test -v -cover -run=^TestWithStreamWindow$
+func TestWithStreamWindow(t *testing.T) {
+	conf := &config{streamWindow: 0}
+	WithStreamWindow(1024)(conf)
+
+	if conf.streamWindow != streamWindowBytes {
+		t.Fatalf("got %d != want %d", conf.streamWindow, streamWindowBytes)
+	}
+
+	WithStreamWindow(1024 * 1024)(conf)
+
+	if conf.streamWindow != 1024*1024 {
+		t.Fatalf("got %d != want %d", conf.streamWindow, 1024*1024)
+	}
+}
@@ -7,6 +7,9 @@ package vex
 import (
 	"context"
 	"errors"
+	"iter"
+	"net"
+	"sync"
 
 	"github.com/FishGoddess/rego"
 )
@@ -18,20 +21,133 @@ var (
 // Status is the status information of pool.
 type Status rego.Status
 
+// clientAvailable returns if the client can send new requests.
+// A client is unavailable if it's closed or the server is going away.
+func clientAvailable(client Client) bool {
+	if ac, ok := client.(interface{ available() bool }); ok {
+		return ac.available()
+	}
+
+	return true
+}
+
+// clientDraining returns if the client is going away and draining the requests in flight.
+// A draining client will close itself after all requests in flight are finished.
+func clientDraining(client Client) bool {
+	if cc, ok := client.(interface{ closed() bool }); ok {
+		return !clientAvailable(client) && !cc.closed()
+	}
+
+	return false
+}
+
+// closedClient returns if the client is closed.
+func closedClient(client Client) bool {
+	if cc, ok := client.(interface{ closed() bool }); ok {
+		return cc.closed()
+	}
+
+	return true
+}
+
+// closeClient closes the client and ignores the error of closing a closed client.
+func closeClient(client Client) error {
+	if err := client.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+		return err
+	}
+
+	return nil
+}
+
 type poolClient struct {
 	pool *Pool
 
 	client Client
+	lock   sync.RWMutex
+}
+
+// availableClient returns the client or a new one dialed if the client is unavailable.
+func (pc *poolClient) availableClient(ctx context.Context) (Client, error) {
+	pc.lock.Lock()
+	defer pc.lock.Unlock()
+
+	if clientAvailable(pc.client) {
+		return pc.client, nil
+	}
+
+	client, err := pc.pool.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = pc.pool.retire(pc.client); err != nil {
+		logger := pc.pool.conf.logger
+		logger.Error("close unavailable client failed", "err", err)
+	}
+
+	pc.client = client
+	return client, nil
 }
 
 // Send sends data and gets a new data.
+// The client will be replaced by a new one transparently if it's unavailable.
 // Returns an error if failed.
-func (pc poolClient) Send(ctx context.Context, data []byte) ([]byte, error) {
-	return pc.client.Send(ctx, data)
+func (pc *poolClient) Send(ctx context.Context, data []byte) ([]byte, error) {
+	client, err := pc.availableClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.Send(ctx, data)
+}
+
+// Notify sends data without waiting for a response.
+// The client will be replaced by a new one transparently if it's unavailable.
+func (pc *poolClient) Notify(ctx context.Context, data []byte) error {
+	client, err := pc.availableClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	return client.Notify(ctx, data)
+}
+
+// SendStream sends data and returns an iterator of the data streamed back by server.
+// The client will be replaced by a new one transparently if it's unavailable.
+func (pc *poolClient) SendStream(ctx context.Context, data []byte) iter.Seq2[[]byte, error] {
+	return func(yield func([]byte, error) bool) {
+		client, err := pc.availableClient(ctx)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		client.SendStream(ctx, data)(yield)
+	}
+}
+
+// OpenStream opens a bidirectional stream multiplexed on the connection.
+// The client will be replaced by a new one transparently if it's unavailable.
+func (pc *poolClient) OpenStream(ctx context.Context) (Stream, error) {
+	client, err := pc.availableClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.OpenStream(ctx)
+}
+
+// Stats returns the statistics of client.
+func (pc *poolClient) Stats() ClientStats {
+	pc.lock.RLock()
+	client := pc.client
+	pc.lock.RUnlock()
+
+	return client.Stats()
 }
 
 // Close returns the client back to the pool and returns an error if failed.
-func (pc poolClient) Close() error {
+func (pc *poolClient) Close() error {
 	ctx := context.Background()
 	return pc.pool.clients.Release(ctx, pc)
 }
@@ -44,31 +160,54 @@ type DialFunc func(ctx context.Context) (Client, error)
 // You should always use a pool instead of using a client in production.
 type Pool struct {
 	conf *config
+	dial DialFunc
 
-	clients *rego.Pool[poolClient]
+	clients *rego.Pool[*poolClient]
+
+	// active are the clients dialed and not closed, and closedStats is the merged statistics of closed clients.
+	// draining are the clients going away which will close themselves after finishing the requests in flight.
+	active      map[*poolClient]struct{}
+	draining    map[Client]struct{}
+	closedStats ClientStats
+	lock        sync.Mutex
 }
 
 // NewPool returns a pool with limit and dial function.
 // Dial function should return a new client as your way and an error if failed.
 func NewPool(limit uint64, dial DialFunc, opts ...Option) *Pool {
 	conf := newConfig().apply(opts...)
-	pool := &Pool{conf: conf}
+	pool := &Pool{conf: conf, dial: dial, active: make(map[*poolClient]struct{}, limit), draining: make(map[Client]struct{})}
 
-	acquire := func(ctx context.Context) (poolClient, error) {
+	acquire := func(ctx context.Context) (*poolClient, error) {
 		client, err := dial(ctx)
 		if err != nil {
-			return poolClient{}, err
+			return nil, err
 		}
 
-		pc := poolClient{pool: pool, client: client}
+		pc := &poolClient{pool: pool, client: client}
+
+		pool.lock.Lock()
+		pool.active[pc] = struct{}{}
+		pool.lock.Unlock()
+
 		return pc, nil
 	}
 
-	release := func(ctx context.Context, pc poolClient) error {
-		return pc.client.Close()
+	release := func(ctx context.Context, pc *poolClient) error {
+		pool.lock.Lock()
+		delete(pool.active, pc)
+		pool.lock.Unlock()
+
+		return pool.retire(pc.client)
+	}
+
+	// Evict the unavailable clients so pool will dial new ones.
+	available := func(ctx context.Context, pc *poolClient) bool {
+		return clientAvailable(pc.client)
 	}
 
 	pool.clients = rego.New(limit, acquire, release)
+	pool.clients.WithAvailableFunc(available)
 	pool.clients.WithPoolClosedErrFunc(func(ctx context.Context) error {
 		return errPoolClosed
 	})
@@ -81,6 +220,68 @@ func (p *Pool) Get(ctx context.Context) (Client, error) {
 	return p.clients.Acquire(ctx)
 }
 
+// retire closes the client and keeps its statistics so the stats of pool won't go backwards.
+// A draining client won't be closed since the server promises to finish its requests in flight.
+func (p *Pool) retire(client Client) error {
+	if clientDraining(client) {
+		p.lock.Lock()
+		p.pruneDraining()
+		p.draining[client] = struct{}{}
+		p.lock.Unlock()
+
+		return nil
+	}
+
+	err := closeClient(client)
+
+	p.lock.Lock()
+	p.keepStats(client)
+	p.lock.Unlock()
+
+	return err
+}
+
+// pruneDraining keeps the statistics of draining clients closed and it should be called with lock.
+func (p *Pool) pruneDraining() {
+	for client := range p.draining {
+		if closedClient(client) {
+			p.keepStats(client)
+			delete(p.draining, client)
+		}
+	}
+}
+
+// keepStats merges the statistics of closed client to pool and it should be called with lock.
+func (p *Pool) keepStats(client Client) {
+	stats := client.Stats()
+	stats.Inflight = 0
+
+	p.closedStats = p.closedStats.merge(stats)
+}
+
+// Stats returns the statistics aggregated across all clients in pool including the closed ones.
+func (p *Pool) Stats() ClientStats {
+	p.lock.Lock()
+	p.pruneDraining()
+
+	stats := p.closedStats
+	for client := range p.draining {
+		stats = stats.merge(client.Stats())
+	}
+
+	clients := make([]*poolClient, 0, len(p.active))
+	for pc := range p.active {
+		clients = append(clients, pc)
+	}
+	p.lock.Unlock()
+
+	for _, pc := range clients {
+		stats = stats.merge(pc.Stats())
+	}
+
+	return stats
+}
+
 // Status returns the status of pool.
 func (p *Pool) Status() Status {
 	status := p.clients.Status()
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"sync/atomic"
 	"testing"
+	"time"
 )
 
 // go test -v -cover -run=^TestNewPool$
@@ -45,7 +46,7 @@ func TestPool(t *testing.T) {
 
 			defer client.Close()
 
-			poolClient, ok := client.(poolClient)
+			poolClient, ok := client.(*poolClient)
 			if !ok {
 				t.Fatalf("got %T is wrong", client)
 			}
@@ -84,3 +85,235 @@ func TestPool(t *testing.T) {
 		t.Fatalf("got %+v != want %+v", err, errPoolClosed)
 	}
 }
+
+// go test -v -cover -run=^TestPoolGoAway$
+func TestPoolGoAway(t *testing.T) {
+	ctx := context.Background()
+
+	newServer := func(name string) (Server, string) {
+		handler := HandlerFunc(func(ctx *Context, data []byte) ([]byte, error) {
+			return []byte(name), nil
+		})
+
+		svr := NewServer("127.0.0.1:0", handler)
+		go svr.Serve()
+
+		time.Sleep(100 * time.Millisecond)
+		return svr, svr.(*server).listener.Addr().String()
+	}
+
+	svrA, addressA := newServer("A")
+	defer svrA.Close()
+
+	svrB, addressB := newServer("B")
+	defer svrB.Close()
+
+	var address atomic.Value
+	address.Store(addressA)
+
+	dial := func(ctx context.Context) (Client, error) {
+		return NewClient(address.Load().(string))
+	}
+
+	pool := NewPool(2, dial)
+	defer pool.Close()
+
+	send := func(client Client, want string) {
+		got, err := client.Send(ctx, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(got) != want {
+			t.Fatalf("got %s != want %s", got, want)
+		}
+	}
+
+	idle, err := pool.Get(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	using, err := pool.Get(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer using.Close()
+
+	send(idle, "A")
+	send(using, "A")
+
+	if err = idle.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	address.Store(addressB)
+
+	if err = svrA.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	// The using client should be replaced transparently.
+	send(using, "B")
+
+	// The idle client should be evicted and a new client will be dialed.
+	client, err := pool.Get(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	send(client, "B")
+}
+
+// go test -v -cover -run=^TestPoolGoAwayInflight$
+func TestPoolGoAwayInflight(t *testing.T) {
+	ctx := context.Background()
+
+	newServer := func(name string) (Server, string) {
+		handler := HandlerFunc(func(ctx *Context, data []byte) ([]byte, error) {
+			if string(data) == "slow" {
+				time.Sleep(500 * time.Millisecond)
+			}
+
+			return []byte(name), nil
+		})
+
+		svr := NewServer("127.0.0.1:0", handler)
+		go svr.Serve()
+
+		time.Sleep(100 * time.Millisecond)
+		return svr, svr.(*server).listener.Addr().String()
+	}
+
+	svrA, addressA := newServer("A")
+	defer svrA.Close()
+
+	svrB, addressB := newServer("B")
+	defer svrB.Close()
+
+	var address atomic.Value
+	address.Store(addressA)
+
+	dial := func(ctx context.Context) (Client, error) {
+		return NewClient(address.Load().(string))
+	}
+
+	pool := NewPool(1, dial)
+	defer pool.Close()
+
+	client, err := pool.Get(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	type result struct {
+		data []byte
+		err  error
+	}
+
+	slow := make(chan result, 1)
+	go func() {
+		data, err := client.Send(ctx, []byte("slow"))
+		slow <- result{data: data, err: err}
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	address.Store(addressB)
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- svrA.Shutdown(ctx)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+
+	// The client going away should be replaced without failing the slow request in flight.
+	data, err := client.Send(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "B" {
+		t.Fatalf("got %s != want %s", data, "B")
+	}
+
+	got := <-slow
+	if got.err != nil {
+		t.Fatal(got.err)
+	}
+
+	if string(got.data) != "A" {
+		t.Fatalf("got %s != want %s", got.data, "A")
+	}
+
+	if err = <-shutdown; err != nil {
+		t.Fatal(err)
+	}
+
+	if stats := pool.Stats(); stats.Sent != 2 || stats.Inflight != 0 || stats.Errors != 0 {
+		t.Fatalf("got %+v is wrong", stats)
+	}
+}
+
+// go test -v -cover -run=^TestPoolStats$
+func TestPoolStats(t *testing.T) {
+	ctx := context.Background()
+
+	address, done, err := runTestServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer done()
+
+	dial := func(ctx context.Context) (Client, error) {
+		return NewClient(address)
+	}
+
+	pool := NewPool(2, dial)
+	defer pool.Close()
+
+	client1, err := pool.Get(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client2, err := pool.Get(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, client := range []Client{client1, client2, client1} {
+		if _, err = client.Send(ctx, []byte("1")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if stats := client1.Stats(); stats.Sent != 2 {
+		t.Fatalf("got %d != want 2", stats.Sent)
+	}
+
+	stats := pool.Stats()
+	if stats.Sent != 3 || stats.Latency.Count != 3 || stats.BytesRead == 0 || stats.BytesWritten == 0 {
+		t.Fatalf("got %+v is wrong", stats)
+	}
+
+	client1.Close()
+	client2.Close()
+
+	// Closing the pool closes all clients, and their stats should be kept.
+	if err = pool.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := pool.Stats(); got.Sent != 3 || got.BytesRead != stats.BytesRead {
+		t.Fatalf("got %+v != want %+v", got, stats)
+	}
+}
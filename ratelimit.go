@@ -0,0 +1,179 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vex
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// RateLimitKeyFunc returns the key of request, and requests with the same key share a token bucket.
+type RateLimitKeyFunc func(ctx *Context) string
+
+// RateLimitByConn limits the requests of each connection.
+func RateLimitByConn(ctx *Context) string {
+	return ctx.RemoteAddress()
+}
+
+// RateLimitByIP limits the requests of each remote ip.
+func RateLimitByIP(ctx *Context) string {
+	return addressIP(ctx.RemoteAddress())
+}
+
+// RateLimitByMethod limits the requests of each method.
+func RateLimitByMethod(ctx *Context) string {
+	return ctx.Method()
+}
+
+// NewRateLimitedError returns a rate limited error carrying the retry after hint.
+func NewRateLimitedError(retryAfter time.Duration) *Error {
+	details := []byte(retryAfter.String())
+	return ErrRateLimited.WithDetails(details)
+}
+
+// RetryAfter returns the retry after hint of a rate limited error.
+// Returns false if err isn't a rate limited error or doesn't have a hint.
+func RetryAfter(err error) (time.Duration, bool) {
+	var vexErr *Error
+	if !errors.As(err, &vexErr) || vexErr.Code != CodeRateLimited {
+		return 0, false
+	}
+
+	retryAfter, err := time.ParseDuration(string(vexErr.Details))
+	if err != nil {
+		return 0, false
+	}
+
+	return retryAfter, true
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type rateLimiter struct {
+	rate    float64
+	burst   float64
+	keyFunc RateLimitKeyFunc
+
+	// fullDuration is the duration of refilling an empty bucket to full.
+	fullDuration time.Duration
+	buckets      map[string]*tokenBucket
+	lastSweep    time.Time
+
+	lock sync.Mutex
+}
+
+func newRateLimiter(rate float64, burst uint64, keyFunc RateLimitKeyFunc) *rateLimiter {
+	fullDuration := time.Duration(float64(burst) / rate * float64(time.Second))
+
+	limiter := &rateLimiter{
+		rate:         rate,
+		burst:        float64(burst),
+		keyFunc:      keyFunc,
+		fullDuration: fullDuration,
+		buckets:      make(map[string]*tokenBucket, 64),
+		lastSweep:    time.Now(),
+	}
+
+	return limiter
+}
+
+// sweep removes the buckets which are full because they're the same as new buckets.
+func (rl *rateLimiter) sweep(now time.Time) {
+	if now.Sub(rl.lastSweep) < rl.fullDuration {
+		return
+	}
+
+	for key, bucket := range rl.buckets {
+		if now.Sub(bucket.last) >= rl.fullDuration {
+			delete(rl.buckets, key)
+		}
+	}
+
+	rl.lastSweep = now
+}
+
+// take takes a token from the bucket of key and returns the duration to wait if there are no tokens.
+func (rl *rateLimiter) take(key string, now time.Time) (retryAfter time.Duration, ok bool) {
+	rl.lock.Lock()
+	defer rl.lock.Unlock()
+
+	rl.sweep(now)
+
+	bucket := rl.buckets[key]
+	if bucket == nil {
+		bucket = &tokenBucket{tokens: rl.burst, last: now}
+		rl.buckets[key] = bucket
+	}
+
+	elapsed := now.Sub(bucket.last).Seconds()
+	bucket.tokens = min(rl.burst, bucket.tokens+elapsed*rl.rate)
+	bucket.last = now
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return 0, true
+	}
+
+	retryAfter = time.Duration((1 - bucket.tokens) / rl.rate * float64(time.Second))
+	return retryAfter, false
+}
+
+// RateLimitInterceptor returns a server interceptor limiting requests with token buckets.
+// Each key returned by keyFunc has a bucket holding burst tokens at most and refilling rate tokens per second.
+// A request will be rejected with a rate limited error carrying the retry after hint if there are no tokens.
+func RateLimitInterceptor(rate float64, burst uint64, keyFunc RateLimitKeyFunc) ServerInterceptor {
+	if rate <= 0 {
+		panic("vex: rate limit rate <= 0")
+	}
+
+	if burst == 0 {
+		panic("vex: rate limit burst == 0")
+	}
+
+	if keyFunc == nil {
+		panic("vex: rate limit key func is nil")
+	}
+
+	limiter := newRateLimiter(rate, burst, keyFunc)
+
+	return func(ctx *Context, data []byte, handler Handler) ([]byte, error) {
+		key := limiter.keyFunc(ctx)
+
+		retryAfter, ok := limiter.take(key, time.Now())
+		if !ok {
+			return nil, NewRateLimitedError(retryAfter)
+		}
+
+		return handler.Handle(ctx, data)
+	}
+}
+
+// RetryRateLimitedInterceptor returns a client interceptor retrying the rate limited requests at most maxRetries times.
+// It waits for the retry after hint before retrying, and returns the error directly if there's no hint.
+func RetryRateLimitedInterceptor(maxRetries int) ClientInterceptor {
+	return func(ctx context.Context, data []byte, send SendFunc) ([]byte, error) {
+		for retries := 0; ; retries++ {
+			result, err := send(ctx, data)
+
+			retryAfter, ok := RetryAfter(err)
+			if !ok || retries >= maxRetries {
+				return result, err
+			}
+
+			timer := time.NewTimer(retryAfter)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, err
+			}
+		}
+	}
+}
@@ -0,0 +1,221 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vex
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// go test -v -cover -run=^TestRateLimitKeyFuncs$
+func TestRateLimitKeyFuncs(t *testing.T) {
+	ctx := &Context{remoteAddress: "127.0.0.1:9876", method: "hello"}
+
+	if got := RateLimitByConn(ctx); got != "127.0.0.1:9876" {
+		t.Fatalf("got %s != want %s", got, "127.0.0.1:9876")
+	}
+
+	if got := RateLimitByIP(ctx); got != "127.0.0.1" {
+		t.Fatalf("got %s != want %s", got, "127.0.0.1")
+	}
+
+	if got := RateLimitByMethod(ctx); got != "hello" {
+		t.Fatalf("got %s != want %s", got, "hello")
+	}
+}
+
+// go test -v -cover -run=^TestRetryAfter$
+func TestRetryAfter(t *testing.T) {
+	err := NewRateLimitedError(1500 * time.Millisecond)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("got %+v != want %+v", err, ErrRateLimited)
+	}
+
+	retryAfter, ok := RetryAfter(err)
+	if !ok || retryAfter != 1500*time.Millisecond {
+		t.Fatalf("got (%s, %+v) != want (%s, true)", retryAfter, ok, 1500*time.Millisecond)
+	}
+
+	if _, ok = RetryAfter(ErrRateLimited); ok {
+		t.Fatal("rate limited error without details has retry after")
+	}
+
+	if _, ok = RetryAfter(ErrInternal.WithDetails([]byte("1s"))); ok {
+		t.Fatal("internal error has retry after")
+	}
+
+	if _, ok = RetryAfter(nil); ok {
+		t.Fatal("nil error has retry after")
+	}
+}
+
+// go test -v -cover -run=^TestRateLimiter$
+func TestRateLimiter(t *testing.T) {
+	limiter := newRateLimiter(10, 2, RateLimitByConn)
+	now := time.Now()
+
+	for i := range 2 {
+		if _, ok := limiter.take("key", now); !ok {
+			t.Fatalf("take %d failed", i)
+		}
+	}
+
+	retryAfter, ok := limiter.take("key", now)
+	if ok {
+		t.Fatal("take succeeded without tokens")
+	}
+
+	if retryAfter != 100*time.Millisecond {
+		t.Fatalf("got %s != want %s", retryAfter, 100*time.Millisecond)
+	}
+
+	if _, ok = limiter.take("other", now); !ok {
+		t.Fatal("take other key failed")
+	}
+
+	now = now.Add(100 * time.Millisecond)
+	if _, ok = limiter.take("key", now); !ok {
+		t.Fatal("take failed after refilling")
+	}
+
+	now = now.Add(time.Second)
+	limiter.take("key", now)
+
+	if len(limiter.buckets) != 1 {
+		t.Fatalf("got %d != want 1", len(limiter.buckets))
+	}
+
+	if limiter.buckets["key"] == nil {
+		t.Fatal("bucket of key is swept")
+	}
+}
+
+// go test -v -cover -run=^TestRateLimitInterceptor$
+func TestRateLimitInterceptor(t *testing.T) {
+	handler := HandlerFunc(func(ctx *Context, data []byte) ([]byte, error) {
+		return data, nil
+	})
+
+	interceptor := RateLimitInterceptor(10, 1, RateLimitByMethod)
+	handler = chainServerInterceptors(handler, []ServerInterceptor{interceptor}).(HandlerFunc)
+
+	ctx := &Context{method: "hello"}
+	if _, err := handler.Handle(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := handler.Handle(ctx, nil)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("got %+v != want %+v", err, ErrRateLimited)
+	}
+
+	if retryAfter, ok := RetryAfter(err); !ok || retryAfter <= 0 {
+		t.Fatalf("got (%s, %+v) is wrong", retryAfter, ok)
+	}
+
+	ctx = &Context{method: "world"}
+	if _, err = handler.Handle(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Fatal("invalid rate limit returns a nil recover")
+			}
+		}()
+
+		RateLimitInterceptor(0, 1, RateLimitByConn)
+	})
+}
+
+// go test -v -cover -run=^TestRetryRateLimitedInterceptor$
+func TestRetryRateLimitedInterceptor(t *testing.T) {
+	sends := 0
+	send := func(ctx context.Context, data []byte) ([]byte, error) {
+		sends++
+		if sends <= 2 {
+			return nil, NewRateLimitedError(time.Millisecond)
+		}
+
+		return data, nil
+	}
+
+	interceptor := RetryRateLimitedInterceptor(2)
+
+	data, err := interceptor(context.Background(), []byte("retry"), send)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "retry" || sends != 3 {
+		t.Fatalf("got (%s, %d) != want (retry, 3)", data, sends)
+	}
+
+	sends = 0
+	interceptor = RetryRateLimitedInterceptor(1)
+
+	if _, err = interceptor(context.Background(), nil, send); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("got %+v != want %+v", err, ErrRateLimited)
+	}
+
+	if sends != 2 {
+		t.Fatalf("got %d != want 2", sends)
+	}
+}
+
+// go test -v -cover -run=^TestServerRateLimit$
+func TestServerRateLimit(t *testing.T) {
+	handler := HandlerFunc(func(ctx *Context, data []byte) ([]byte, error) {
+		return data, nil
+	})
+
+	interceptor := RateLimitInterceptor(10, 1, RateLimitByIP)
+	svr := NewServer("127.0.0.1:0", handler, WithServerInterceptors(interceptor))
+
+	go func() {
+		if err := svr.Serve(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	defer svr.Close()
+
+	time.Sleep(100 * time.Millisecond)
+	address := svr.(*server).listener.Addr().String()
+
+	client, err := NewClient(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	if _, err = client.Send(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.Send(context.Background(), nil)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("got %+v != want %+v", err, ErrRateLimited)
+	}
+
+	if retryAfter, ok := RetryAfter(err); !ok || retryAfter <= 0 || retryAfter > 100*time.Millisecond {
+		t.Fatalf("got (%s, %+v) is wrong", retryAfter, ok)
+	}
+
+	retryClient, err := NewClient(address, WithClientInterceptors(RetryRateLimitedInterceptor(3)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer retryClient.Close()
+
+	if _, err = retryClient.Send(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+}
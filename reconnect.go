@@ -0,0 +1,298 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vex
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+var (
+	errReconnecting = NewError(CodeUnavailable, "vex: client is reconnecting")
+)
+
+// State is the connection state of reconnecting client.
+type State uint8
+
+const (
+	StateConnected State = iota + 1
+	StateDisconnected
+	StateReconnecting
+	StateClosed
+)
+
+// String returns the name of state.
+func (s State) String() string {
+	switch s {
+	case StateConnected:
+		return "connected"
+	case StateDisconnected:
+		return "disconnected"
+	case StateReconnecting:
+		return "reconnecting"
+	case StateClosed:
+		return "closed"
+	default:
+		return "unknown"
+	}
+}
+
+// StateFunc is called when the state of reconnecting client changes.
+// The err is the reason of disconnection or reconnection failure, and it's nil in other states.
+type StateFunc func(state State, err error)
+
+type reconnectClient struct {
+	conf *config
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	address string
+	client  *client
+	state   State
+
+	// closedStats is the merged statistics of clients which are disconnected.
+	closedStats ClientStats
+
+	lock sync.RWMutex
+}
+
+func newReconnectClient(address string, conf *config) (*reconnectClient, error) {
+	client, err := newClient(address, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	rc := new(reconnectClient)
+	rc.conf = conf
+	rc.ctx = ctx
+	rc.cancel = cancel
+	rc.address = address
+	rc.client = client
+
+	rc.setState(StateConnected, nil)
+	go rc.watch(client)
+	return rc, nil
+}
+
+func (rc *reconnectClient) setState(state State, err error) {
+	rc.lock.Lock()
+	if rc.state == StateClosed {
+		rc.lock.Unlock()
+		return
+	}
+
+	rc.state = state
+	rc.lock.Unlock()
+
+	if rc.conf.stateFunc != nil {
+		rc.conf.stateFunc(state, err)
+	}
+}
+
+// backoff returns the delay before the attempt which has exponential growth and jitter.
+func (rc *reconnectClient) backoff(attempt int) time.Duration {
+	delay := rc.conf.reconnectMinDelay << min(attempt, 32)
+	if delay <= 0 || delay > rc.conf.reconnectMaxDelay {
+		delay = rc.conf.reconnectMaxDelay
+	}
+
+	// Use the half of delay as the base and add a random jitter to it.
+	half := delay / 2
+	return half + rand.N(half+1)
+}
+
+func (rc *reconnectClient) watch(client *client) {
+	select {
+	case <-client.ctx.Done():
+	case <-rc.ctx.Done():
+		return
+	}
+
+	cause := context.Cause(client.ctx)
+	rc.conf.logger.Error("client disconnected", "address", rc.address, "err", cause)
+
+	rc.lock.Lock()
+	if rc.client == client {
+		rc.client = nil
+	}
+
+	rc.closedStats = rc.closedStats.merge(client.Stats())
+	rc.lock.Unlock()
+
+	rc.setState(StateDisconnected, cause)
+	rc.reconnect()
+}
+
+func (rc *reconnectClient) reconnect() {
+	logger := rc.conf.logger
+
+	for attempt := 0; ; attempt++ {
+		timer := time.NewTimer(rc.backoff(attempt))
+
+		select {
+		case <-timer.C:
+		case <-rc.ctx.Done():
+			timer.Stop()
+			return
+		}
+
+		rc.setState(StateReconnecting, nil)
+
+		client, err := newClient(rc.address, rc.conf)
+		if err != nil {
+			logger.Error("client reconnect failed", "address", rc.address, "attempt", attempt, "err", err)
+
+			rc.setState(StateDisconnected, err)
+			continue
+		}
+
+		rc.lock.Lock()
+		if rc.ctx.Err() != nil {
+			rc.lock.Unlock()
+
+			client.Close()
+			return
+		}
+
+		rc.client = client
+		rc.lock.Unlock()
+
+		logger.Info("client reconnected", "address", rc.address, "attempt", attempt)
+
+		rc.setState(StateConnected, nil)
+		go rc.watch(client)
+		return
+	}
+}
+
+func (rc *reconnectClient) currentClient() (*client, error) {
+	rc.lock.RLock()
+	client := rc.client
+	rc.lock.RUnlock()
+
+	if rc.ctx.Err() != nil {
+		return nil, errClientClosed
+	}
+
+	if client == nil {
+		return nil, errReconnecting
+	}
+
+	return client, nil
+}
+
+// wrapError wraps err with errReconnecting if it's caused by the lost connection of client.
+func (rc *reconnectClient) wrapError(client *client, err error) error {
+	if err != nil && client.ctx.Err() != nil && rc.ctx.Err() == nil {
+		return fmt.Errorf("%w: %w", errReconnecting, err)
+	}
+
+	return err
+}
+
+// Send sends data and gets a new data.
+// Returns an error which is ErrUnavailable if the connection is lost, and you can retry it later.
+func (rc *reconnectClient) Send(ctx context.Context, data []byte) ([]byte, error) {
+	client, err := rc.currentClient()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = client.Send(ctx, data)
+	if err = rc.wrapError(client, err); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// Notify sends data without waiting for a response.
+// Returns an error which is ErrUnavailable if the connection is lost, and you can retry it later.
+func (rc *reconnectClient) Notify(ctx context.Context, data []byte) error {
+	client, err := rc.currentClient()
+	if err != nil {
+		return err
+	}
+
+	err = client.Notify(ctx, data)
+	return rc.wrapError(client, err)
+}
+
+// SendStream sends data and returns an iterator of the data streamed back by server.
+// The error yielded is ErrUnavailable if the connection is lost, and you can retry it later.
+func (rc *reconnectClient) SendStream(ctx context.Context, data []byte) iter.Seq2[[]byte, error] {
+	return func(yield func([]byte, error) bool) {
+		client, err := rc.currentClient()
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		for data, err := range client.SendStream(ctx, data) {
+			if !yield(data, rc.wrapError(client, err)) {
+				return
+			}
+		}
+	}
+}
+
+// OpenStream opens a bidirectional stream on the current connection.
+// Returns an error which is ErrUnavailable if the connection is lost, and you can retry it later.
+func (rc *reconnectClient) OpenStream(ctx context.Context) (Stream, error) {
+	client, err := rc.currentClient()
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := client.OpenStream(ctx)
+	if err = rc.wrapError(client, err); err != nil {
+		return nil, err
+	}
+
+	return stream, nil
+}
+
+// Stats returns the statistics of client including the disconnected ones.
+func (rc *reconnectClient) Stats() ClientStats {
+	rc.lock.RLock()
+	client := rc.client
+	stats := rc.closedStats
+	rc.lock.RUnlock()
+
+	if client == nil {
+		return stats
+	}
+
+	return stats.merge(client.Stats())
+}
+
+// available returns if the client isn't closed and it will reconnect by itself.
+func (rc *reconnectClient) available() bool {
+	return rc.ctx.Err() == nil
+}
+
+// Close closes the client and stops reconnecting.
+func (rc *reconnectClient) Close() error {
+	rc.lock.Lock()
+	client := rc.client
+	rc.client = nil
+	rc.cancel()
+	rc.lock.Unlock()
+
+	rc.setState(StateClosed, nil)
+
+	if client == nil {
+		return nil
+	}
+
+	return client.Close()
+}
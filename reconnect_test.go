@@ -0,0 +1,152 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vex
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// go test -v -cover -run=^TestState$
+func TestState(t *testing.T) {
+	testCases := map[State]string{
+		StateConnected:    "connected",
+		StateDisconnected: "disconnected",
+		StateReconnecting: "reconnecting",
+		StateClosed:       "closed",
+		0:                 "unknown",
+	}
+
+	for state, want := range testCases {
+		if got := state.String(); got != want {
+			t.Fatalf("got %s != want %s", got, want)
+		}
+	}
+}
+
+// go test -v -cover -run=^TestReconnectClientBackoff$
+func TestReconnectClientBackoff(t *testing.T) {
+	conf := newConfig().apply(WithReconnect(100*time.Millisecond, time.Second))
+	rc := &reconnectClient{conf: conf}
+
+	testCases := []struct {
+		attempt int
+		delay   time.Duration
+	}{
+		{attempt: 0, delay: 100 * time.Millisecond},
+		{attempt: 1, delay: 200 * time.Millisecond},
+		{attempt: 3, delay: 800 * time.Millisecond},
+		{attempt: 4, delay: time.Second},
+		{attempt: 100, delay: time.Second},
+	}
+
+	for _, testCase := range testCases {
+		for range 100 {
+			backoff := rc.backoff(testCase.attempt)
+			if backoff < testCase.delay/2 || backoff > testCase.delay {
+				t.Fatalf("attempt %d: backoff %s not in [%s, %s]", testCase.attempt, backoff, testCase.delay/2, testCase.delay)
+			}
+		}
+	}
+}
+
+// go test -v -cover -run=^TestReconnectClient$
+func TestReconnectClient(t *testing.T) {
+	handler := new(testHandler)
+
+	svr := NewServer("127.0.0.1:0", handler)
+	go svr.Serve()
+
+	time.Sleep(100 * time.Millisecond)
+	address := svr.(*server).listener.Addr().String()
+
+	var states []State
+	var lock sync.Mutex
+
+	stateFunc := func(state State, err error) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		states = append(states, state)
+	}
+
+	waitState := func(state State) {
+		for range 100 {
+			lock.Lock()
+			last := states[len(states)-1]
+			lock.Unlock()
+
+			if last == state {
+				return
+			}
+
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		t.Fatalf("wait state %s timeout", state)
+	}
+
+	client, err := NewClient(address, WithReconnect(10*time.Millisecond, 50*time.Millisecond), WithStateFunc(stateFunc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if _, err = client.Send(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = svr.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	waitState(StateDisconnected)
+
+	_, err = client.Send(ctx, nil)
+	if !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("error %+v is not %+v", err, ErrUnavailable)
+	}
+
+	svr = NewServer(address, handler)
+	go svr.Serve()
+	defer svr.Close()
+
+	waitState(StateConnected)
+
+	if _, err = client.Send(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// The stats of disconnected client should be kept.
+	if stats := client.Stats(); stats.Sent != 2 {
+		t.Fatalf("got %d != want 2", stats.Sent)
+	}
+
+	if err = client.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = client.Send(ctx, nil); err != errClientClosed {
+		t.Fatalf("got %+v != want %+v", err, errClientClosed)
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	// Reconnecting may fail several times before the server restarts.
+	states = slices.Compact(states)
+	for len(states) > 5 && states[2] == StateReconnecting && states[3] == StateDisconnected {
+		states = slices.Delete(states, 2, 4)
+	}
+
+	want := []State{StateConnected, StateDisconnected, StateReconnecting, StateConnected, StateClosed}
+	if !slices.Equal(states, want) {
+		t.Fatalf("got %+v != want %+v", states, want)
+	}
+}
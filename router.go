@@ -0,0 +1,184 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vex
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// Router routes the data to handlers registered by method.
+// Client should use ContextWithMethod to specify the method to call.
+type Router struct {
+	handlers map[string]Handler
+	streams  map[string]StreamHandler
+	bidis    map[string]BidiStreamHandler
+	lock     sync.RWMutex
+}
+
+// NewRouter returns a new router without any handlers.
+func NewRouter() *Router {
+	router := &Router{
+		handlers: make(map[string]Handler, 16),
+		streams:  make(map[string]StreamHandler, 16),
+		bidis:    make(map[string]BidiStreamHandler, 16),
+	}
+
+	return router
+}
+
+// Register registers the handler with method to router.
+// It panics if method is empty, handler is nil or method is already registered.
+func (r *Router) Register(method string, handler Handler) {
+	if method == "" {
+		panic("vex: router method is empty")
+	}
+
+	if handler == nil {
+		panic("vex: router handler is nil")
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.checkRegistered(method)
+	r.handlers[method] = handler
+}
+
+// checkRegistered panics if method is already registered as any kind of handler.
+func (r *Router) checkRegistered(method string) {
+	_, ok := r.handlers[method]
+	_, streamOK := r.streams[method]
+	_, bidiOK := r.bidis[method]
+
+	if ok || streamOK || bidiOK {
+		panic("vex: router method " + method + " is already registered")
+	}
+}
+
+// RegisterFunc registers the handler function with method to router.
+func (r *Router) RegisterFunc(method string, handler func(ctx *Context, data []byte) ([]byte, error)) {
+	r.Register(method, HandlerFunc(handler))
+}
+
+// RegisterStream registers the stream handler with method to router.
+// It panics if method is empty, handler is nil or method is already registered.
+func (r *Router) RegisterStream(method string, handler StreamHandler) {
+	if method == "" {
+		panic("vex: router method is empty")
+	}
+
+	if handler == nil {
+		panic("vex: router handler is nil")
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.checkRegistered(method)
+	r.streams[method] = handler
+}
+
+// RegisterStreamFunc registers the stream handler function with method to router.
+func (r *Router) RegisterStreamFunc(method string, handler func(ctx *Context, data []byte, stream StreamSender) error) {
+	r.RegisterStream(method, StreamHandlerFunc(handler))
+}
+
+// RegisterBidiStream registers the bidirectional stream handler with method to router.
+// It panics if method is empty, handler is nil or method is already registered.
+func (r *Router) RegisterBidiStream(method string, handler BidiStreamHandler) {
+	if method == "" {
+		panic("vex: router method is empty")
+	}
+
+	if handler == nil {
+		panic("vex: router handler is nil")
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.checkRegistered(method)
+	r.bidis[method] = handler
+}
+
+// RegisterBidiStreamFunc registers the bidirectional stream handler function with method to router.
+func (r *Router) RegisterBidiStreamFunc(method string, handler func(ctx *Context, stream ServerStream) error) {
+	r.RegisterBidiStream(method, BidiStreamHandlerFunc(handler))
+}
+
+// Methods returns all methods registered to router in order, including the stream ones.
+func (r *Router) Methods() []string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	methods := make([]string, 0, len(r.handlers)+len(r.streams)+len(r.bidis))
+	for method := range r.handlers {
+		methods = append(methods, method)
+	}
+
+	for method := range r.streams {
+		methods = append(methods, method)
+	}
+
+	for method := range r.bidis {
+		methods = append(methods, method)
+	}
+
+	slices.Sort(methods)
+	return methods
+}
+
+// Handle finds the handler of method in context and calls it.
+// Returns an error with CodeMethodNotFound if no handler is registered with the method.
+func (r *Router) Handle(ctx *Context, data []byte) ([]byte, error) {
+	method := ctx.Method()
+
+	r.lock.RLock()
+	handler, ok := r.handlers[method]
+	r.lock.RUnlock()
+
+	if !ok {
+		message := fmt.Sprintf("vex: method %q not found", method)
+		return nil, NewError(CodeMethodNotFound, message)
+	}
+
+	return handler.Handle(ctx, data)
+}
+
+// HandleStream finds the stream handler of method in context and calls it.
+// Returns an error with CodeMethodNotFound if no stream handler is registered with the method.
+func (r *Router) HandleStream(ctx *Context, data []byte, stream StreamSender) error {
+	method := ctx.Method()
+
+	r.lock.RLock()
+	handler, ok := r.streams[method]
+	r.lock.RUnlock()
+
+	if !ok {
+		message := fmt.Sprintf("vex: stream method %q not found", method)
+		return NewError(CodeMethodNotFound, message)
+	}
+
+	return handler.HandleStream(ctx, data, stream)
+}
+
+// HandleBidiStream finds the bidirectional stream handler of method in context and calls it.
+// Returns an error with CodeMethodNotFound if no bidirectional stream handler is registered with the method.
+func (r *Router) HandleBidiStream(ctx *Context, stream ServerStream) error {
+	method := ctx.Method()
+
+	r.lock.RLock()
+	handler, ok := r.bidis[method]
+	r.lock.RUnlock()
+
+	if !ok {
+		message := fmt.Sprintf("vex: bidi stream method %q not found", method)
+		return NewError(CodeMethodNotFound, message)
+	}
+
+	return handler.HandleBidiStream(ctx, stream)
+}
@@ -0,0 +1,223 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vex
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// go test -v -cover -run=^TestRouterRegister$
+func TestRouterRegister(t *testing.T) {
+	router := NewRouter()
+	handler := new(testHandler)
+
+	router.Register("echo", handler)
+	router.RegisterFunc("hello", func(ctx *Context, data []byte) ([]byte, error) {
+		return []byte("hello"), nil
+	})
+
+	router.RegisterStreamFunc("count", func(ctx *Context, data []byte, stream StreamSender) error {
+		return nil
+	})
+
+	router.RegisterBidiStreamFunc("chat", func(ctx *Context, stream ServerStream) error {
+		return nil
+	})
+
+	got := router.Methods()
+	want := []string{"chat", "count", "echo", "hello"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("got %+v != want %+v", got, want)
+	}
+
+	panicCases := map[string]func(){
+		"empty method": func() { router.Register("", handler) },
+		"nil handler":  func() { router.Register("nil", nil) },
+		"registered":   func() { router.Register("echo", handler) },
+
+		"empty stream method": func() { router.RegisterStream("", StreamHandlerFunc(nil)) },
+		"nil stream handler":  func() { router.RegisterStream("nil", nil) },
+		"registered stream":   func() { router.RegisterStream("count", StreamHandlerFunc(nil)) },
+		"registered as both":  func() { router.Register("count", handler) },
+
+		"empty bidi stream method": func() { router.RegisterBidiStream("", BidiStreamHandlerFunc(nil)) },
+		"nil bidi stream handler":  func() { router.RegisterBidiStream("nil", nil) },
+		"registered bidi stream":   func() { router.RegisterStream("chat", StreamHandlerFunc(nil)) },
+	}
+
+	for name, panicCase := range panicCases {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Fatalf("%s returns a nil recover", name)
+				}
+			}()
+
+			panicCase()
+		})
+	}
+}
+
+// go test -v -cover -run=^TestRouterHandle$
+func TestRouterHandle(t *testing.T) {
+	router := NewRouter()
+	router.RegisterFunc("hello", func(ctx *Context, data []byte) ([]byte, error) {
+		return []byte("hello " + string(data)), nil
+	})
+
+	ctx := &Context{method: "hello"}
+
+	data, err := router.Handle(ctx, []byte("vex"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "hello vex" {
+		t.Fatalf("got %s != want %s", data, "hello vex")
+	}
+
+	ctx = &Context{method: "bye"}
+
+	_, err = router.Handle(ctx, []byte("vex"))
+	if err == nil {
+		t.Fatal("router handle returns a nil error")
+	}
+
+	want := `vex: method "bye" not found`
+	if err.Error() != want {
+		t.Fatalf("got %s != want %s", err.Error(), want)
+	}
+
+	if !errors.Is(err, ErrMethodNotFound) {
+		t.Fatalf("error %+v is not %+v", err, ErrMethodNotFound)
+	}
+}
+
+type testStreamSender struct {
+	data [][]byte
+}
+
+func (tss *testStreamSender) Send(data []byte) error {
+	tss.data = append(tss.data, data)
+	return nil
+}
+
+// go test -v -cover -run=^TestRouterHandleStream$
+func TestRouterHandleStream(t *testing.T) {
+	router := NewRouter()
+	router.RegisterStreamFunc("hello", func(ctx *Context, data []byte, stream StreamSender) error {
+		return stream.Send([]byte("hello " + string(data)))
+	})
+
+	ctx := &Context{method: "hello"}
+	stream := new(testStreamSender)
+
+	if err := router.HandleStream(ctx, []byte("vex"), stream); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stream.data) != 1 || string(stream.data[0]) != "hello vex" {
+		t.Fatalf("got %s != want %s", stream.data, "[hello vex]")
+	}
+
+	ctx = &Context{method: "bye"}
+
+	err := router.HandleStream(ctx, []byte("vex"), stream)
+	if !errors.Is(err, ErrMethodNotFound) {
+		t.Fatalf("error %+v is not %+v", err, ErrMethodNotFound)
+	}
+}
+
+func (tss *testStreamSender) Recv() ([]byte, error) {
+	return []byte("vex"), nil
+}
+
+// go test -v -cover -run=^TestRouterHandleBidiStream$
+func TestRouterHandleBidiStream(t *testing.T) {
+	router := NewRouter()
+	router.RegisterBidiStreamFunc("hello", func(ctx *Context, stream ServerStream) error {
+		data, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		return stream.Send([]byte("hello " + string(data)))
+	})
+
+	ctx := &Context{method: "hello"}
+	stream := new(testStreamSender)
+
+	if err := router.HandleBidiStream(ctx, stream); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stream.data) != 1 || string(stream.data[0]) != "hello vex" {
+		t.Fatalf("got %s != want %s", stream.data, "[hello vex]")
+	}
+
+	ctx = &Context{method: "bye"}
+
+	err := router.HandleBidiStream(ctx, stream)
+	if !errors.Is(err, ErrMethodNotFound) {
+		t.Fatalf("error %+v is not %+v", err, ErrMethodNotFound)
+	}
+}
+
+// go test -v -cover -run=^TestRouterServer$
+func TestRouterServer(t *testing.T) {
+	router := NewRouter()
+	router.RegisterFunc("method", func(ctx *Context, data []byte) ([]byte, error) {
+		return []byte(ctx.Method()), nil
+	})
+
+	svr := NewServer("127.0.0.1:0", router)
+
+	go func() {
+		if err := svr.Serve(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	defer svr.Close()
+
+	time.Sleep(100 * time.Millisecond)
+	address := svr.(*server).listener.Addr().String()
+
+	client, err := NewClient(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	ctx := ContextWithMethod(context.Background(), "method")
+
+	data, err := client.Send(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "method" {
+		t.Fatalf("got %s != want %s", data, "method")
+	}
+
+	_, err = client.Send(context.Background(), nil)
+	if err == nil {
+		t.Fatal("send returns a nil error")
+	}
+
+	want := `vex: method "" not found`
+	if err.Error() != want {
+		t.Fatalf("got %s != want %s", err.Error(), want)
+	}
+
+	if !errors.Is(err, ErrMethodNotFound) {
+		t.Fatalf("error %+v is not %+v", err, ErrMethodNotFound)
+	}
+}
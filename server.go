@@ -7,19 +7,29 @@ package vex
 import (
 	"bufio"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"io"
+	"maps"
 	"net"
 	"os"
 	"os/signal"
+	"runtime/debug"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 
 	packets "github.com/FishGoddess/vex/internal/packet"
 )
 
 var (
 	errServerAlreadyServing = errors.New("vex: server is already serving")
+	errServerShuttingDown   = NewError(CodeUnavailable, "vex: server is shutting down")
+	errTooManyConns         = NewError(CodeUnavailable, "vex: too many connections")
+	errTooManyConnsPerIP    = NewError(CodeUnavailable, "vex: too many connections from the same ip")
 )
 
 // Handler is for handling the data from client and returns the new data or an error if failed.
@@ -27,9 +37,19 @@ type Handler interface {
 	Handle(ctx *Context, data []byte) ([]byte, error)
 }
 
+// HandlerFunc is a function implementing Handler.
+type HandlerFunc func(ctx *Context, data []byte) ([]byte, error)
+
+// Handle handles the data by calling the function itself.
+func (hf HandlerFunc) Handle(ctx *Context, data []byte) ([]byte, error) {
+	return hf(ctx, data)
+}
+
 // Server is the interface of vex server.
 type Server interface {
 	Serve() error
+	ServeListener(listener net.Listener) error
+	Shutdown(ctx context.Context) error
 	Close() error
 }
 
@@ -41,15 +61,23 @@ type server struct {
 
 	address  string
 	listener net.Listener
-	conns    map[uint64]net.Conn
+	conns    map[uint64]*serverConn
 	connID   uint64
+	ipConns  map[string]uint64
+	limit    chan struct{}
 	handler  Handler
+	requests atomic.Int64
+	draining atomic.Bool
+
+	streamHandler     StreamHandler
+	bidiStreamHandler BidiStreamHandler
 
 	group sync.WaitGroup
 	lock  sync.RWMutex
 }
 
 // NewServer creates a server with address and handler.
+// The handler can also implement StreamHandler and BidiStreamHandler to handle streams, like Router.
 func NewServer(address string, handler Handler, opts ...Option) Server {
 	conf := newConfig().apply(opts...)
 
@@ -68,29 +96,62 @@ func NewServer(address string, handler Handler, opts ...Option) Server {
 	server.ctx = ctx
 	server.cancel = cancel
 	server.address = address
-	server.conns = make(map[uint64]net.Conn, 64)
+	server.conns = make(map[uint64]*serverConn, 64)
 	server.connID = 0
-	server.handler = handler
+	server.ipConns = make(map[string]uint64, 64)
+	server.handler = chainServerInterceptors(handler, conf.serverInterceptors)
+	server.streamHandler, _ = handler.(StreamHandler)
+	server.bidiStreamHandler, _ = handler.(BidiStreamHandler)
+
+	if conf.maxConns > 0 {
+		server.limit = make(chan struct{}, conf.maxConns)
+	}
+
+	if len(conf.signals) > 0 {
+		go server.watchSignals()
+	}
 
-	go server.watchSignals()
 	return server
 }
 
 func (s *server) watchSignals() {
 	logger := s.conf.logger
 
+	signals := s.conf.signals
+	if s.conf.reloadFunc != nil && !slices.Contains(signals, os.Signal(syscall.SIGHUP)) {
+		signals = append(slices.Clone(signals), syscall.SIGHUP)
+	}
+
 	signalCh := make(chan os.Signal, 1)
-	signal.Notify(signalCh, syscall.SIGHUP, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGTERM)
+	signal.Notify(signalCh, signals...)
+	defer signal.Stop(signalCh)
 
-	select {
-	case sg := <-signalCh:
-		logger.Info("received a signal", "signal", sg)
+	for {
+		select {
+		case sg := <-signalCh:
+			logger.Info("received a signal", "signal", sg)
+
+			if s.conf.signalFunc != nil {
+				s.conf.signalFunc(sg)
+			}
+
+			if sg == syscall.SIGHUP && s.conf.reloadFunc != nil {
+				if err := s.conf.reloadFunc(); err != nil {
+					logger.Error("reload server failed", "err", err)
+				}
+
+				continue
+			}
 
-		if err := s.Close(); err != nil {
-			logger.Error("close server failed", "err", err)
+			if err := s.Close(); err != nil {
+				logger.Error("close server failed", "err", err)
+			}
+
+			return
+		case <-s.ctx.Done():
+			logger.Debug("server context is done")
+			return
 		}
-	case <-s.ctx.Done():
-		logger.Debug("server context is done")
 	}
 }
 
@@ -99,56 +160,622 @@ func (s *server) nextConnID() uint64 {
 	return s.connID
 }
 
-func (s *server) handlePacket(conn net.Conn, reader io.Reader, writer io.Writer) error {
-	packet, err := packets.ReadPacket(reader)
-	if err != nil {
+type serverConn struct {
+	server *server
+
+	conn     net.Conn
+	reader   *bufio.Reader
+	writer   io.Writer
+	limit    chan struct{}
+	cancels  map[uint64]context.CancelFunc
+	streams  map[uint64]*serverStream
+	lastRead atomic.Int64
+	done     chan struct{}
+
+	// pending queues the tasks waiting for the limit and pendingSignal notifies the limit loop.
+	pending       []func(handle bool)
+	pendingSignal chan struct{}
+
+	peerCertificate *x509.Certificate
+
+	// lastID is the last request id accepted before going away.
+	lastID    uint64
+	goingAway bool
+
+	group     sync.WaitGroup
+	writeLock sync.Mutex
+	lock      sync.Mutex
+}
+
+func newServerConn(server *server, conn net.Conn) *serverConn {
+	sc := &serverConn{
+		server:  server,
+		conn:    conn,
+		reader:  bufio.NewReader(countReader{reader: conn, count: server.conf.metrics.BytesRead}),
+		writer:  countWriter{writer: conn, count: server.conf.metrics.BytesWritten},
+		cancels: make(map[uint64]context.CancelFunc, 16),
+		streams: make(map[uint64]*serverStream, 16),
+		done:    make(chan struct{}),
+	}
+
+	if concurrency := server.conf.connConcurrency; concurrency > 0 {
+		sc.limit = make(chan struct{}, concurrency)
+		sc.pendingSignal = make(chan struct{}, 1)
+	}
+
+	return sc
+}
+
+func (sc *serverConn) writePacket(packet packets.Packet) error {
+	sc.writeLock.Lock()
+	defer sc.writeLock.Unlock()
+
+	return packets.WritePacket(sc.writer, packet)
+}
+
+func (sc *serverConn) recoverPanic(ctx *Context, recovered any) error {
+	handler := sc.server.conf.panicHandler
+	if handler == nil {
+		logger := sc.server.conf.logger
+		logger.Error("handle packet panicked", "panic", recovered, "method", ctx.Method(), "stack", string(debug.Stack()))
+		return ErrInternal
+	}
+
+	err := handler(ctx, recovered)
+	if err == nil {
+		return ErrInternal
+	}
+
+	if CodeOf(err) == CodeUnknown {
+		return NewError(CodeInternal, err.Error())
+	}
+
+	return err
+}
+
+func (sc *serverConn) handle(ctx *Context, data []byte) (result []byte, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			result = nil
+			err = sc.recoverPanic(ctx, recovered)
+		}
+	}()
+
+	return sc.server.handler.Handle(ctx, data)
+}
+
+func (sc *serverConn) handleStream(ctx *Context, stream *serverStream, packet packets.Packet, data []byte) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = sc.recoverPanic(ctx, recovered)
+		}
+	}()
+
+	stream.ctx = ctx
+
+	// Run the stream inside the interceptors so the requests rejected by them won't open a stream.
+	handler := HandlerFunc(func(ctx *Context, data []byte) ([]byte, error) {
+		return nil, sc.runStream(ctx, stream, packet, data)
+	})
+
+	_, err = chainServerInterceptors(handler, sc.server.conf.serverInterceptors).Handle(ctx, data)
+	return err
+}
+
+func (sc *serverConn) runStream(ctx *Context, stream *serverStream, packet packets.Packet, data []byte) error {
+	// The client closes its sending side in the open packet if it only sends the data of open packet.
+	if packet.IsEndOfStream() {
+		if sc.server.streamHandler == nil {
+			return errStreamNotSupported
+		}
+
+		return sc.server.streamHandler.HandleStream(ctx, data, stream)
+	}
+
+	if sc.server.bidiStreamHandler == nil {
+		return errStreamNotSupported
+	}
+
+	if err := stream.receiver.growWindow(stream.id); err != nil {
 		return err
 	}
 
+	return sc.server.bidiStreamHandler.HandleBidiStream(ctx, stream)
+}
+
+// requestContext returns the context of request which has a deadline if packet has a timeout.
+// It should be called right after reading the packet so the time waiting in queue will be counted.
+// The context can be canceled by a cancel packet with the same id before the returned done is called.
+func (sc *serverConn) requestContext(packet packets.Packet) (ctx context.Context, done func()) {
+	var cancel context.CancelFunc
+	if timeout := packet.Timeout(); timeout > 0 {
+		ctx, cancel = context.WithTimeout(sc.server.ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(sc.server.ctx)
+	}
+
+	id := packet.ID()
+
+	sc.lock.Lock()
+	sc.cancels[id] = cancel
+	sc.lock.Unlock()
+
+	done = func() {
+		sc.lock.Lock()
+		delete(sc.cancels, id)
+		sc.lock.Unlock()
+
+		cancel()
+	}
+
+	return ctx, done
+}
+
+func (sc *serverConn) cancelRequest(id uint64) {
+	sc.lock.Lock()
+	cancel := sc.cancels[id]
+	sc.lock.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+}
+
+func (sc *serverConn) handlePacket(requestCtx context.Context, packet packets.Packet, stream *serverStream) {
+	logger := sc.server.conf.logger
+
+	if err := requestCtx.Err(); err != nil {
+		logger.Debug("skip packet", "err", err, "id", packet.ID())
+		return
+	}
+
 	data, err := packet.Data()
 	if err != nil {
-		return err
+		logger.Error("read packet data failed", "err", err, "id", packet.ID())
+		return
 	}
 
-	ctx := acquireContext(s.ctx, conn)
+	ctx := acquireContext(requestCtx, sc.conn)
+	ctx.method = packet.Method()
+	ctx.metadata = packet.Metadata()
+	ctx.peerCertificate = sc.peerCertificate
+	ctx.contentType = ContentType(packet.ContentType())
 	defer releaseContext(ctx)
 
-	data, err = s.handler.Handle(ctx, data)
+	begin := time.Now()
+	if stream != nil {
+		data, err = nil, sc.handleStream(ctx, stream, packet, data)
+	} else {
+		data, err = sc.handle(ctx, data)
+	}
+
+	// Record the methods not found with an empty method so the labels of metrics won't grow without bound.
+	method := ctx.method
+	if err != nil && CodeOf(err) == CodeMethodNotFound {
+		method = ""
+	}
+
+	sc.server.conf.metrics.RequestHandled(method, err, time.Since(begin))
+
+	// The client doesn't wait for the response of a one way packet, so log the error instead.
+	if packet.IsOneWay() {
+		if err != nil {
+			logger.Error("handle one way packet failed", "err", err, "id", packet.ID(), "method", ctx.method)
+		}
+
+		return
+	}
+
+	// The client won't wait for the response of a canceled request, so we don't need to send it.
+	if requestCtx.Err() == context.Canceled {
+		logger.Debug("request canceled", "id", packet.ID())
+		return
+	}
+
+	response := packets.New(packet.ID())
+	response.SetMetadata(ctx.responseMetadata)
+
+	// The response of stream is the end of it which carries the error only.
+	if stream != nil {
+		response.SetStream()
+		response.SetEndOfStream()
+	}
+
+	response.SetContentType(uint8(ctx.responseContentType))
+
 	if err != nil {
-		packet.SetError(err)
+		setPacketError(&response, err)
 	} else {
-		packet.SetData(data)
+		response.SetData(data)
+	}
+
+	err = sc.writePacket(response)
+	if errors.Is(err, net.ErrClosed) {
+		logger.Debug("write packet closed", "err", err, "id", packet.ID())
+		return
 	}
 
-	err = packets.WritePacket(writer, packet)
 	if err != nil {
+		logger.Error("write packet failed", "err", err, "id", packet.ID())
+	}
+}
+
+func (sc *serverConn) pong(ping packets.Packet) {
+	pong := packets.New(ping.ID())
+	pong.SetPong()
+
+	if err := sc.writePacket(pong); err != nil {
+		logger := sc.server.conf.logger
+		logger.Debug("write pong packet failed", "err", err)
+	}
+}
+
+func (sc *serverConn) heartbeatLoop() {
+	logger := sc.server.conf.logger
+
+	ticker := time.NewTicker(sc.server.conf.heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			lastRead := time.Unix(0, sc.lastRead.Load())
+			if time.Since(lastRead) > sc.server.conf.heartbeatTimeout {
+				logger.Error("heartbeat timeout", "address", sc.conn.RemoteAddr(), "last_read", lastRead)
+
+				sc.conn.Close()
+				return
+			}
+
+			ping := packets.New(0)
+			ping.SetPing()
+
+			if err := sc.writePacket(ping); err != nil {
+				logger.Debug("write ping packet failed", "err", err)
+			}
+		case <-sc.done:
+			return
+		}
+	}
+}
+
+// goAway tells the client the connection is going away and the id of goAway packet is the last
+// request id the server will process. Requests with a greater id will be rejected.
+func (sc *serverConn) goAway() {
+	sc.lock.Lock()
+	if sc.goingAway {
+		sc.lock.Unlock()
+
+		return
+	}
+
+	sc.goingAway = true
+	lastID := sc.lastID
+	sc.lock.Unlock()
+
+	goAway := packets.New(lastID)
+	goAway.SetGoAway()
+
+	if err := sc.writePacket(goAway); err != nil {
+		logger := sc.server.conf.logger
+		logger.Debug("write go away packet failed", "err", err)
+	}
+}
+
+// dispatchStream dispatches the packet to its stream and drops it if the stream is finished.
+func (sc *serverConn) dispatchStream(packet packets.Packet) {
+	sc.lock.Lock()
+	stream := sc.streams[packet.ID()]
+	sc.lock.Unlock()
+
+	if stream != nil {
+		stream.dispatch(packet)
+	}
+}
+
+func (sc *serverConn) reject(packet packets.Packet, err error) {
+	response := packets.New(packet.ID())
+	setPacketError(&response, err)
+
+	if err = sc.writePacket(response); err != nil {
+		logger := sc.server.conf.logger
+		logger.Debug("write reject packet failed", "err", err, "id", packet.ID())
+	}
+}
+
+func (sc *serverConn) dispatch(packet packets.Packet) {
+	if packet.IsPing() {
+		sc.pong(packet)
+		return
+	}
+
+	if packet.IsPong() {
+		return
+	}
+
+	if packet.IsCancel() {
+		sc.cancelRequest(packet.ID())
+		return
+	}
+
+	if packet.IsStream() && !packet.IsOpenStream() {
+		sc.dispatchStream(packet)
+		return
+	}
+
+	// Count the request before checking going away so shutdown won't miss it.
+	requests := &sc.server.requests
+	requests.Add(1)
+
+	sc.lock.Lock()
+	if sc.goingAway {
+		sc.lock.Unlock()
+
+		requests.Add(-1)
+
+		if packet.IsOneWay() {
+			logger := sc.server.conf.logger
+			logger.Debug("drop one way packet", "err", errServerShuttingDown, "id", packet.ID())
+			return
+		}
+
+		sc.reject(packet, errServerShuttingDown)
+		return
+	}
+
+	sc.lastID = max(sc.lastID, packet.ID())
+
+	// Register the stream before handling so the packets following it won't be dropped.
+	var stream *serverStream
+	if packet.IsOpenStream() {
+		stream = newServerStream(sc, packet.ID())
+		sc.streams[packet.ID()] = stream
+	}
+
+	sc.lock.Unlock()
+
+	ctx, requestDone := sc.requestContext(packet)
+	done := func() {
+		if stream != nil {
+			sc.lock.Lock()
+			delete(sc.streams, packet.ID())
+			sc.lock.Unlock()
+		}
+
+		requestDone()
+		requests.Add(-1)
+	}
+
+	// Streams may last for a long time so they aren't limited, otherwise they will starve the requests.
+	if sc.limit == nil || stream != nil {
+		sc.group.Go(func() {
+			defer done()
+
+			sc.handlePacket(ctx, packet, stream)
+		})
+
+		return
+	}
+
+	// Queue the packet instead of waiting for the limit so the reading of conn won't be blocked,
+	// otherwise the ping, pong and cancel packets can't be handled in time.
+	sc.lock.Lock()
+	sc.pending = append(sc.pending, func(handle bool) {
+		defer done()
+
+		if handle {
+			sc.handlePacket(ctx, packet, stream)
+		}
+	})
+	sc.lock.Unlock()
+
+	select {
+	case sc.pendingSignal <- struct{}{}:
+	default:
+	}
+}
+
+// popPending pops a pending task or returns a channel to wait if there is no pending tasks.
+func (sc *serverConn) popPending() (func(handle bool), <-chan struct{}) {
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+
+	if len(sc.pending) == 0 {
+		return nil, sc.pendingSignal
+	}
+
+	task := sc.pending[0]
+	sc.pending[0] = nil
+	sc.pending = sc.pending[1:]
+	return task, nil
+}
+
+// limitLoop runs the pending tasks in order once the limit is acquired.
+// The pending tasks will be skipped after the conn is done.
+func (sc *serverConn) limitLoop() {
+	for {
+		select {
+		case sc.limit <- struct{}{}:
+		case <-sc.done:
+			sc.skipPending()
+			return
+		}
+
+		task, pushed := sc.popPending()
+		for task == nil {
+			select {
+			case <-pushed:
+				task, pushed = sc.popPending()
+			case <-sc.done:
+				<-sc.limit
+				sc.skipPending()
+				return
+			}
+		}
+
+		sc.group.Go(func() {
+			defer func() { <-sc.limit }()
+
+			task(true)
+		})
+	}
+}
+
+func (sc *serverConn) skipPending() {
+	for {
+		task, _ := sc.popPending()
+		if task == nil {
+			return
+		}
+
+		task(false)
+	}
+}
+
+// handshake completes the tls handshake of conn and records the certificate of client.
+// The handshake should be completed in dial timeout, otherwise it will be failed.
+func (sc *serverConn) handshake() error {
+	tlsConn, ok := sc.conn.(*tls.Conn)
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(sc.server.ctx, sc.server.conf.dialTimeout)
+	defer cancel()
+
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
 		return err
 	}
 
+	state := tlsConn.ConnectionState()
+	if len(state.PeerCertificates) > 0 {
+		sc.peerCertificate = state.PeerCertificates[0]
+	}
+
 	return nil
 }
 
-func (s *server) handleConn(conn net.Conn) {
-	logger := s.conf.logger
+func (sc *serverConn) serve() {
+	logger := sc.server.conf.logger
+
+	defer func() {
+		close(sc.done)
+		sc.group.Wait()
+	}()
+
+	if err := sc.handshake(); err != nil {
+		logger.Error("tls handshake failed", "err", err, "address", sc.conn.RemoteAddr())
+		return
+	}
+
+	sc.lastRead.Store(time.Now().UnixNano())
+
+	if sc.server.conf.heartbeatInterval > 0 {
+		go sc.heartbeatLoop()
+	}
+
+	if sc.limit != nil {
+		sc.group.Go(sc.limitLoop)
+	}
 
-	reader := bufio.NewReader(conn)
-	writer := conn
 	for {
-		err := s.handlePacket(conn, reader, writer)
+		packet, err := packets.ReadPacket(sc.reader)
 		if err == io.EOF {
-			logger.Debug("handle packet eof", "err", err)
+			logger.Debug("read packet eof", "err", err)
 			return
 		}
 
 		if errors.Is(err, net.ErrClosed) {
-			logger.Debug("handle packet closed", "err", err)
+			logger.Debug("read packet closed", "err", err)
 			return
 		}
 
 		if err != nil {
-			logger.Error("handle packet failed", "err", err)
+			logger.Error("read packet failed", "err", err)
 			return
 		}
+
+		sc.lastRead.Store(time.Now().UnixNano())
+		sc.dispatch(packet)
+	}
+}
+
+// addressIP returns the ip of address or the whole address if it doesn't have a port.
+func addressIP(address string) string {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return address
+	}
+
+	return host
+}
+
+// rejectConn sends an error packet to the conn before closing it.
+func (s *server) rejectConn(conn net.Conn, err error) {
+	logger := s.conf.logger
+	logger.Error("reject conn", "err", err, "address", conn.RemoteAddr())
+
+	defer conn.Close()
+
+	packet := packets.New(0)
+	setPacketError(&packet, err)
+
+	// Use a deadline to avoid blocking on a slow client, including the tls handshake.
+	conn.SetDeadline(time.Now().Add(s.conf.dialTimeout))
+	if err = packets.WritePacket(conn, packet); err != nil {
+		logger.Debug("write reject packet failed", "err", err, "address", conn.RemoteAddr())
+	}
+}
+
+// acquireLimit acquires a conn from the limit and returns false if failed.
+// It blocks until some conns are closed or the server is closed if block is true.
+func (s *server) acquireLimit(block bool) bool {
+	if s.limit == nil {
+		return true
+	}
+
+	if !block {
+		select {
+		case s.limit <- struct{}{}:
+			return true
+		default:
+			return false
+		}
+	}
+
+	select {
+	case s.limit <- struct{}{}:
+		return true
+	case <-s.ctx.Done():
+		return false
+	}
+}
+
+func (s *server) releaseLimit() {
+	if s.limit != nil {
+		<-s.limit
+	}
+}
+
+// acquireIP acquires a conn of ip and returns false if the ip reaches the limit.
+func (s *server) acquireIP(ip string) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if maxConns := s.conf.maxConnsPerIP; maxConns > 0 && s.ipConns[ip] >= maxConns {
+		return false
+	}
+
+	s.ipConns[ip]++
+	return true
+}
+
+func (s *server) releaseIP(ip string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.ipConns[ip]--; s.ipConns[ip] == 0 {
+		delete(s.ipConns, ip)
 	}
 }
 
@@ -157,7 +784,18 @@ func (s *server) serve() error {
 	logger.Info("server is serving", "address", s.address)
 
 	for {
+		// Acquire the limit before accepting so the listener stops accepting when the limit is reached.
+		blocking := s.conf.connLimitPolicy == ConnLimitBlock
+		if blocking && !s.acquireLimit(true) {
+			logger.Info("server is closed", "address", s.address)
+			break
+		}
+
 		conn, err := s.listener.Accept()
+		if err != nil && blocking {
+			s.releaseLimit()
+		}
+
 		if errors.Is(err, net.ErrClosed) {
 			logger.Info("listener is closed", "address", s.address)
 			break
@@ -168,11 +806,42 @@ func (s *server) serve() error {
 			continue
 		}
 
+		if !blocking && !s.acquireLimit(false) {
+			s.group.Go(func() { s.rejectConn(conn, errTooManyConns) })
+			continue
+		}
+
+		ip := addressIP(conn.RemoteAddr().String())
+		if !s.acquireIP(ip) {
+			s.releaseLimit()
+			s.group.Go(func() { s.rejectConn(conn, errTooManyConnsPerIP) })
+			continue
+		}
+
+		release := func() {
+			s.releaseIP(ip)
+			s.releaseLimit()
+		}
+
 		s.lock.Lock()
+		if s.conns == nil {
+			s.lock.Unlock()
+
+			release()
+			conn.Close()
+			break
+		}
+
+		sc := newServerConn(s, conn)
 		connID := s.nextConnID()
-		s.conns[connID] = conn
+		s.conns[connID] = sc
 		s.lock.Unlock()
 
+		// The conn may be missed by shutdown if it's accepted before closing listener.
+		if s.draining.Load() {
+			sc.goAway()
+		}
+
 		s.group.Go(func() {
 			defer conn.Close()
 
@@ -180,10 +849,15 @@ func (s *server) serve() error {
 				s.lock.Lock()
 				delete(s.conns, connID)
 				s.lock.Unlock()
+
+				release()
 			}()
 
+			s.conf.metrics.ConnAccepted()
+			defer s.conf.metrics.ConnClosed()
+
 			logger.Info("handle conn start", "address", conn.RemoteAddr())
-			s.handleConn(conn)
+			sc.serve()
 			logger.Info("handle conn end", "address", conn.RemoteAddr())
 		})
 	}
@@ -191,6 +865,14 @@ func (s *server) serve() error {
 	return nil
 }
 
+func (s *server) setListener(listener net.Listener) {
+	if s.conf.tlsConfig != nil {
+		listener = tls.NewListener(listener, s.conf.tlsConfig)
+	}
+
+	s.listener = listener
+}
+
 // Serve serves on address and returns an error if failed.
 func (s *server) Serve() error {
 	logger := s.conf.logger
@@ -203,34 +885,97 @@ func (s *server) Serve() error {
 		return errServerAlreadyServing
 	}
 
-	var lc net.ListenConfig
-
-	listener, err := lc.Listen(s.ctx, "tcp", s.address)
+	listener, err := s.conf.transport.Listen(s.ctx, s.address)
 	if err != nil {
 		s.lock.Unlock()
 
-		logger.Error("listen tcp failed", "err", err, "address", s.address)
+		logger.Error("listen failed", "err", err, "address", s.address)
 		return err
 	}
 
-	s.listener = listener
+	s.setListener(listener)
 	s.lock.Unlock()
 	return s.serve()
 }
 
+// ServeListener serves on the listener and returns an error if failed.
+// The listener will be closed when the server is closed.
+func (s *server) ServeListener(listener net.Listener) error {
+	logger := s.conf.logger
+
+	s.lock.Lock()
+	if s.listener != nil {
+		s.lock.Unlock()
+
+		logger.Error("server is already serving", "address", s.address)
+		return errServerAlreadyServing
+	}
+
+	s.setListener(listener)
+	s.lock.Unlock()
+	return s.serve()
+}
+
+// Shutdown shuts down the server gracefully like http.Server.Shutdown.
+// It stops accepting new connections, sends a go away packet to clients and rejects new requests,
+// then waits for the inflight requests to finish before closing the server.
+// If the context is done before all requests finish, the server will be closed forcibly and the
+// error of context will be returned.
+func (s *server) Shutdown(ctx context.Context) error {
+	logger := s.conf.logger
+	logger.Info("server is shutting down", "address", s.address)
+
+	s.draining.Store(true)
+
+	s.lock.Lock()
+	if s.listener != nil {
+		if err := s.listener.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+			s.lock.Unlock()
+
+			return err
+		}
+	}
+
+	conns := slices.Collect(maps.Values(s.conns))
+	s.lock.Unlock()
+
+	for _, sc := range conns {
+		sc.goAway()
+	}
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	for s.requests.Load() > 0 {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			logger.Error("shutdown timeout", "address", s.address, "requests", s.requests.Load())
+
+			if err := s.Close(); err != nil {
+				return err
+			}
+
+			return ctx.Err()
+		}
+	}
+
+	return s.Close()
+}
+
 // Close closes the server and returns an error if failed.
 func (s *server) Close() error {
 	s.lock.Lock()
 	if s.listener != nil {
-		if err := s.listener.Close(); err != nil {
+		if err := s.listener.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
 			s.lock.Unlock()
 
 			return err
 		}
 	}
 
-	for _, conn := range s.conns {
-		if err := conn.Close(); err != nil {
+	for _, sc := range s.conns {
+		if err := sc.conn.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
 			s.lock.Unlock()
 
 			return err
@@ -5,11 +5,22 @@
 package vex
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"errors"
+	"fmt"
+	"io"
+	"math/big"
 	"net"
 	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"testing"
 	"time"
@@ -80,7 +91,7 @@ func TestNewServer(t *testing.T) {
 			}
 		}()
 
-		svr := NewServer("127.0.0.1:0", handler)
+		svr := NewServer("127.0.0.1:0", handler, WithSignals())
 
 		go func() {
 			if err := svr.Serve(); err != nil {
@@ -168,7 +179,10 @@ func TestServerHandler(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		time.Sleep(time.Millisecond)
+		readPacket, err := packets.ReadPacket(conn)
+		if err != nil {
+			t.Fatal(err)
+		}
 
 		got := string(handler.data)
 		want := strings.Repeat("test\n", i)
@@ -176,11 +190,6 @@ func TestServerHandler(t *testing.T) {
 			t.Fatalf("%d: got %s != want %s", i, got, want)
 		}
 
-		readPacket, err := packets.ReadPacket(conn)
-		if err != nil {
-			t.Fatal(err)
-		}
-
 		if readPacket.ID() != id {
 			t.Fatalf("%d: got %d != want %d", i, readPacket.ID(), id)
 		}
@@ -252,7 +261,10 @@ func TestServerError(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		time.Sleep(time.Millisecond)
+		readPacket, err := packets.ReadPacket(conn)
+		if err != nil {
+			t.Fatal(err)
+		}
 
 		got := string(handler.data)
 		want := strings.Repeat("test\n", i)
@@ -260,11 +272,6 @@ func TestServerError(t *testing.T) {
 			t.Fatalf("%d: got %s != want %s", i, got, want)
 		}
 
-		readPacket, err := packets.ReadPacket(conn)
-		if err != nil {
-			t.Fatal(err)
-		}
-
 		if readPacket.ID() != id {
 			t.Fatalf("%d: got %d != want %d", i, readPacket.ID(), id)
 		}
@@ -322,7 +329,10 @@ func TestServerErrorHandler(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		time.Sleep(time.Millisecond)
+		readPacket, err := packets.ReadPacket(conn)
+		if err != nil {
+			t.Fatal(err)
+		}
 
 		got := string(handler.data)
 		want := strings.Repeat("test\n", i)
@@ -330,11 +340,6 @@ func TestServerErrorHandler(t *testing.T) {
 			t.Fatalf("%d: got %s != want %s", i, got, want)
 		}
 
-		readPacket, err := packets.ReadPacket(conn)
-		if err != nil {
-			t.Fatal(err)
-		}
-
 		if readPacket.ID() != id {
 			t.Fatalf("%d: got %d != want %d", i, readPacket.ID(), id)
 		}
@@ -359,3 +364,1086 @@ func TestServerErrorHandler(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+type testSlowHandler struct {
+	running atomic.Int64
+	peak    atomic.Int64
+}
+
+func (h *testSlowHandler) Handle(ctx *Context, data []byte) ([]byte, error) {
+	running := h.running.Add(1)
+	defer h.running.Add(-1)
+
+	for {
+		peak := h.peak.Load()
+		if running <= peak || h.peak.CompareAndSwap(peak, running) {
+			break
+		}
+	}
+
+	if string(data) == "slow" {
+		time.Sleep(200 * time.Millisecond)
+	}
+
+	return data, nil
+}
+
+// go test -v -cover -run=^TestServerConcurrency$
+func TestServerConcurrency(t *testing.T) {
+	handler := new(testSlowHandler)
+	svr := NewServer("127.0.0.1:0", handler)
+
+	go func() {
+		if err := svr.Serve(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	address := svr.(*server).listener.Addr().String()
+
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer conn.Close()
+
+	for i, data := range []string{"slow", "fast"} {
+		packet := packets.New(uint64(i + 1))
+		packet.SetData([]byte(data))
+
+		if err = packets.WritePacket(conn, packet); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, want := range []uint64{2, 1} {
+		packet, err := packets.ReadPacket(conn)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if packet.ID() != want {
+			t.Fatalf("got %d != want %d", packet.ID(), want)
+		}
+	}
+
+	if err := svr.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// go test -v -cover -run=^TestServerConnConcurrency$
+func TestServerConnConcurrency(t *testing.T) {
+	handler := new(testSlowHandler)
+	svr := NewServer("127.0.0.1:0", handler, WithConnConcurrency(2))
+
+	go func() {
+		if err := svr.Serve(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	address := svr.(*server).listener.Addr().String()
+
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer conn.Close()
+
+	for i := range 6 {
+		packet := packets.New(uint64(i + 1))
+		packet.SetData([]byte("slow"))
+
+		if err = packets.WritePacket(conn, packet); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for range 6 {
+		if _, err := packets.ReadPacket(conn); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if peak := handler.peak.Load(); peak != 2 {
+		t.Fatalf("got %d != want 2", peak)
+	}
+
+	if err := svr.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// go test -v -cover -run=^TestServerConnConcurrencyHeartbeat$
+func TestServerConnConcurrencyHeartbeat(t *testing.T) {
+	handler := HandlerFunc(func(ctx *Context, data []byte) ([]byte, error) {
+		time.Sleep(600 * time.Millisecond)
+		return data, nil
+	})
+
+	heartbeat := WithHeartbeat(50*time.Millisecond, 200*time.Millisecond)
+	svr := NewServer("127.0.0.1:0", handler, WithConnConcurrency(1), heartbeat)
+
+	go func() {
+		if err := svr.Serve(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	defer svr.Close()
+
+	time.Sleep(100 * time.Millisecond)
+	address := svr.(*server).listener.Addr().String()
+
+	client, err := NewClient(address, heartbeat)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	var wg sync.WaitGroup
+	for i := range 2 {
+		wg.Go(func() {
+			want := fmt.Sprintf("slow %d", i)
+
+			data, err := client.Send(context.Background(), []byte(want))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			if string(data) != want {
+				t.Errorf("got %s != want %s", data, want)
+			}
+		})
+	}
+
+	wg.Wait()
+}
+
+// go test -v -cover -run=^TestServerPanic$
+func TestServerPanic(t *testing.T) {
+	handler := HandlerFunc(func(ctx *Context, data []byte) ([]byte, error) {
+		if string(data) == "panic" {
+			panic(string(data))
+		}
+
+		return data, nil
+	})
+
+	errPanic := errors.New("panic handled")
+	panicHandler := func(ctx *Context, recovered any) error {
+		if recovered == "panic" {
+			return errPanic
+		}
+
+		return nil
+	}
+
+	testCases := []struct {
+		opts []Option
+		want string
+	}{
+		{opts: nil, want: ErrInternal.Error()},
+		{opts: []Option{WithPanicHandler(panicHandler)}, want: errPanic.Error()},
+	}
+
+	for _, testCase := range testCases {
+		svr := NewServer("127.0.0.1:0", handler, testCase.opts...)
+
+		go func() {
+			if err := svr.Serve(); err != nil {
+				t.Error(err)
+			}
+		}()
+
+		time.Sleep(100 * time.Millisecond)
+		address := svr.(*server).listener.Addr().String()
+
+		conn, err := net.Dial("tcp", address)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i, data := range []string{"panic", "ok"} {
+			packet := packets.New(uint64(i + 1))
+			packet.SetData([]byte(data))
+
+			if err = packets.WritePacket(conn, packet); err != nil {
+				t.Fatal(err)
+			}
+
+			packet, err = packets.ReadPacket(conn)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := packet.Data()
+			if data == "ok" {
+				if err != nil || string(got) != data {
+					t.Fatalf("got %s, %+v != want %s", got, err, data)
+				}
+
+				continue
+			}
+
+			if err == nil || err.Error() != testCase.want {
+				t.Fatalf("got %+v != want %s", err, testCase.want)
+			}
+
+			if packet.ErrorCode() != uint32(CodeInternal) {
+				t.Fatalf("got %d != want %d", packet.ErrorCode(), CodeInternal)
+			}
+		}
+
+		conn.Close()
+
+		if err := svr.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// go test -v -cover -run=^TestServerDeadline$
+func TestServerDeadline(t *testing.T) {
+	handler := HandlerFunc(func(ctx *Context, data []byte) ([]byte, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			return nil, errors.New("no deadline")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+			return data, nil
+		}
+	})
+
+	svr := NewServer("127.0.0.1:0", handler)
+
+	go func() {
+		if err := svr.Serve(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	defer svr.Close()
+
+	time.Sleep(100 * time.Millisecond)
+	address := svr.(*server).listener.Addr().String()
+
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer conn.Close()
+
+	packet := packets.New(1)
+	packet.SetTimeout(100 * time.Millisecond)
+
+	if err = packets.WritePacket(conn, packet); err != nil {
+		t.Fatal(err)
+	}
+
+	begin := time.Now()
+
+	packet, err = packets.ReadPacket(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cost := time.Since(begin); cost > 500*time.Millisecond {
+		t.Fatalf("handler costs %s without honoring deadline", cost)
+	}
+
+	if packet.ErrorCode() != uint32(CodeTimeout) {
+		t.Fatalf("got %d != want %d", packet.ErrorCode(), CodeTimeout)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	time.Sleep(10 * time.Millisecond)
+
+	client, err := NewClient(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	if _, err = client.Send(ctx, nil); err != context.DeadlineExceeded {
+		t.Fatalf("got %+v != want %+v", err, context.DeadlineExceeded)
+	}
+}
+
+// go test -v -cover -run=^TestServerSkipExpired$
+func TestServerSkipExpired(t *testing.T) {
+	var handled atomic.Int64
+	handler := HandlerFunc(func(ctx *Context, data []byte) ([]byte, error) {
+		handled.Add(1)
+		time.Sleep(200 * time.Millisecond)
+		return data, nil
+	})
+
+	svr := NewServer("127.0.0.1:0", handler, WithConnConcurrency(1))
+
+	go func() {
+		if err := svr.Serve(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	defer svr.Close()
+
+	time.Sleep(100 * time.Millisecond)
+	address := svr.(*server).listener.Addr().String()
+
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer conn.Close()
+
+	for i, timeout := range []time.Duration{0, 50 * time.Millisecond, 0} {
+		packet := packets.New(uint64(i + 1))
+		packet.SetTimeout(timeout)
+
+		if err = packets.WritePacket(conn, packet); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, want := range []uint64{1, 3} {
+		packet, err := packets.ReadPacket(conn)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if packet.ID() != want {
+			t.Fatalf("got %d != want %d", packet.ID(), want)
+		}
+	}
+
+	if got := handled.Load(); got != 2 {
+		t.Fatalf("got %d != want 2", got)
+	}
+}
+
+// go test -v -cover -run=^TestServerCancel$
+func TestServerCancel(t *testing.T) {
+	canceled := make(chan error, 1)
+	handler := HandlerFunc(func(ctx *Context, data []byte) ([]byte, error) {
+		select {
+		case <-ctx.Done():
+			canceled <- ctx.Err()
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+			canceled <- nil
+			return data, nil
+		}
+	})
+
+	svr := NewServer("127.0.0.1:0", handler)
+
+	go func() {
+		if err := svr.Serve(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	defer svr.Close()
+
+	time.Sleep(100 * time.Millisecond)
+	address := svr.(*server).listener.Addr().String()
+
+	client, err := NewClient(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	if _, err = client.Send(ctx, nil); err != context.Canceled {
+		t.Fatalf("got %+v != want %+v", err, context.Canceled)
+	}
+
+	select {
+	case err := <-canceled:
+		if err != context.Canceled {
+			t.Fatalf("got %+v != want %+v", err, context.Canceled)
+		}
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("handler isn't canceled")
+	}
+
+	sc := newServerConn(svr.(*server), nil)
+	sc.cancelRequest(1)
+
+	_, done := sc.requestContext(packets.New(1))
+	if len(sc.cancels) != 1 {
+		t.Fatalf("got %d != want 1", len(sc.cancels))
+	}
+
+	done()
+	if len(sc.cancels) != 0 {
+		t.Fatalf("got %d != want 0", len(sc.cancels))
+	}
+}
+
+// go test -v -cover -run=^TestServerHeartbeat$
+func TestServerHeartbeat(t *testing.T) {
+	svr := NewServer("127.0.0.1:0", new(testHandler), WithHeartbeat(20*time.Millisecond, 100*time.Millisecond))
+
+	go func() {
+		if err := svr.Serve(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	defer svr.Close()
+
+	time.Sleep(100 * time.Millisecond)
+	address := svr.(*server).listener.Addr().String()
+
+	t.Run("alive client", func(t *testing.T) {
+		client, err := NewClient(address)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer client.Close()
+
+		time.Sleep(300 * time.Millisecond)
+
+		data, err := client.Send(context.Background(), []byte("alive"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(data) != "alive" {
+			t.Fatalf("got %s != want %s", data, "alive")
+		}
+	})
+
+	t.Run("dead client", func(t *testing.T) {
+		conn, err := net.Dial("tcp", address)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer conn.Close()
+
+		begin := time.Now()
+		for {
+			packet, err := packets.ReadPacket(conn)
+			if err == io.EOF {
+				break
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !packet.IsPing() {
+				t.Fatalf("packet %+v isn't ping", packet)
+			}
+		}
+
+		if cost := time.Since(begin); cost > 500*time.Millisecond {
+			t.Fatalf("heartbeat timeout costs %s", cost)
+		}
+	})
+}
+
+func newTestCertificate(t *testing.T, template *x509.Certificate, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (tls.Certificate, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if parent == nil {
+		parent = template
+		parentKey = key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tlsCert := tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: cert}
+	return tlsCert, cert
+}
+
+func newTestTLSConfigs(t *testing.T) (serverConfig *tls.Config, clientConfig *tls.Config) {
+	notBefore := time.Now().Add(-time.Hour)
+	notAfter := time.Now().Add(time.Hour)
+
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "vex ca"},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+
+	caCert, ca := newTestCertificate(t, caTemplate, nil, nil)
+	caKey := caCert.PrivateKey.(*ecdsa.PrivateKey)
+
+	serverTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "vex server"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	clientTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "vex client"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	serverCert, _ := newTestCertificate(t, serverTemplate, ca, caKey)
+	clientCert, _ := newTestCertificate(t, clientTemplate, ca, caKey)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+
+	serverConfig = &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	}
+
+	clientConfig = &tls.Config{
+		Certificates: []tls.Certificate{clientCert},
+		RootCAs:      pool,
+	}
+
+	return serverConfig, clientConfig
+}
+
+// go test -v -cover -run=^TestServerTLS$
+func TestServerTLS(t *testing.T) {
+	serverConfig, clientConfig := newTestTLSConfigs(t)
+
+	handler := HandlerFunc(func(ctx *Context, data []byte) ([]byte, error) {
+		cert := ctx.PeerCertificate()
+		if cert == nil {
+			return nil, ErrUnauthorized
+		}
+
+		return []byte(cert.Subject.CommonName), nil
+	})
+
+	svr := NewServer("127.0.0.1:0", handler, WithTLSConfig(serverConfig))
+
+	go func() {
+		if err := svr.Serve(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	defer svr.Close()
+
+	time.Sleep(100 * time.Millisecond)
+	address := svr.(*server).listener.Addr().String()
+
+	client, err := NewClient(address, WithTLSConfig(clientConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	data, err := client.Send(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "vex client" {
+		t.Fatalf("got %s != want %s", data, "vex client")
+	}
+
+	noCertConfig := clientConfig.Clone()
+	noCertConfig.Certificates = nil
+
+	client, err = NewClient(address, WithTLSConfig(noCertConfig))
+	if err == nil {
+		// TLS 1.3 reports the verification failure of client certificate after handshake.
+		defer client.Close()
+
+		if _, err = client.Send(context.Background(), nil); err == nil {
+			t.Fatal("send without certificate returns a nil error")
+		}
+	}
+
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer conn.Close()
+
+	packet := packets.New(1)
+	if err = packets.WritePacket(conn, packet); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = packets.ReadPacket(conn); err == nil {
+		t.Fatal("read packet without tls returns a nil error")
+	}
+}
+
+// go test -v -cover -run=^TestServerServeListener$
+func TestServerServeListener(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	address := listener.Addr().String()
+	svr := NewServer(address, new(testHandler))
+
+	go func() {
+		if err := svr.ServeListener(listener); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+
+	if err := svr.ServeListener(listener); err != errServerAlreadyServing {
+		t.Fatalf("got %+v != want %+v", err, errServerAlreadyServing)
+	}
+
+	client, err := NewClient(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	data, err := client.Send(context.Background(), []byte("listener"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "listener" {
+		t.Fatalf("got %s != want %s", data, "listener")
+	}
+
+	if err = svr.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = listener.Accept(); !errors.Is(err, net.ErrClosed) {
+		t.Fatalf("got %+v != want %+v", err, net.ErrClosed)
+	}
+}
+
+// go test -v -cover -run=^TestServerShutdown$
+func TestServerShutdown(t *testing.T) {
+	handler := HandlerFunc(func(ctx *Context, data []byte) ([]byte, error) {
+		time.Sleep(200 * time.Millisecond)
+		return data, nil
+	})
+
+	svr := NewServer("127.0.0.1:0", handler)
+
+	go func() {
+		if err := svr.Serve(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	defer svr.Close()
+
+	time.Sleep(100 * time.Millisecond)
+	address := svr.(*server).listener.Addr().String()
+
+	client, err := NewClient(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	shutdown := make(chan error, 1)
+	time.AfterFunc(50*time.Millisecond, func() {
+		shutdown <- svr.Shutdown(context.Background())
+	})
+
+	want := []byte("inflight")
+
+	got, err := client.Send(context.Background(), want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != string(want) {
+		t.Fatalf("got %s != want %s", got, want)
+	}
+
+	if err = <-shutdown; err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = NewClient(address); err == nil {
+		t.Fatal("new client after shutdown should be failed")
+	}
+}
+
+// go test -v -cover -run=^TestServerShutdownDraining$
+func TestServerShutdownDraining(t *testing.T) {
+	handler := HandlerFunc(func(ctx *Context, data []byte) ([]byte, error) {
+		time.Sleep(200 * time.Millisecond)
+		return data, nil
+	})
+
+	svr := NewServer("127.0.0.1:0", handler)
+
+	go func() {
+		if err := svr.Serve(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	defer svr.Close()
+
+	time.Sleep(100 * time.Millisecond)
+	address := svr.(*server).listener.Addr().String()
+
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer conn.Close()
+
+	request := packets.New(1)
+	request.SetData([]byte("inflight"))
+
+	if err = packets.WritePacket(conn, request); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- svr.Shutdown(context.Background())
+	}()
+
+	goAway, err := packets.ReadPacket(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !goAway.IsGoAway() {
+		t.Fatalf("packet %+v isn't go away", goAway)
+	}
+
+	if goAway.ID() != 1 {
+		t.Fatalf("got %d != want 1", goAway.ID())
+	}
+
+	request = packets.New(2)
+	if err = packets.WritePacket(conn, request); err != nil {
+		t.Fatal(err)
+	}
+
+	rejected, err := packets.ReadPacket(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rejected.ID() != 2 {
+		t.Fatalf("got %d != want 2", rejected.ID())
+	}
+
+	if _, err = packetData(&rejected); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("got %+v != want %+v", err, ErrUnavailable)
+	}
+
+	response, err := packets.ReadPacket(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := response.Data()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if response.ID() != 1 || string(data) != "inflight" {
+		t.Fatalf("got %+v is wrong", response)
+	}
+
+	if err = <-shutdown; err != nil {
+		t.Fatal(err)
+	}
+}
+
+// go test -v -cover -run=^TestServerShutdownTimeout$
+func TestServerShutdownTimeout(t *testing.T) {
+	handler := HandlerFunc(func(ctx *Context, data []byte) ([]byte, error) {
+		time.Sleep(time.Second)
+		return data, nil
+	})
+
+	svr := NewServer("127.0.0.1:0", handler)
+
+	go func() {
+		if err := svr.Serve(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	address := svr.(*server).listener.Addr().String()
+
+	client, err := NewClient(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	sent := make(chan error, 1)
+	go func() {
+		_, err := client.Send(context.Background(), nil)
+		sent <- err
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if err = svr.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("got %+v != want %+v", err, context.DeadlineExceeded)
+	}
+
+	select {
+	case err = <-sent:
+		if err == nil {
+			t.Fatal("send should be failed after shutdown timeout")
+		}
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("send isn't finished after shutdown timeout")
+	}
+}
+
+// go test -v -cover -run=^TestServerSignals$
+func TestServerSignals(t *testing.T) {
+	var reloaded atomic.Int64
+	reloadFunc := func() error {
+		reloaded.Add(1)
+		return nil
+	}
+
+	signalCh := make(chan os.Signal, 4)
+	signalFunc := func(signal os.Signal) {
+		signalCh <- signal
+	}
+
+	handler := new(testHandler)
+	svr := NewServer("127.0.0.1:0", handler, WithSignals(syscall.SIGUSR1), WithSignalFunc(signalFunc), WithReloadFunc(reloadFunc))
+
+	go func() {
+		if err := svr.Serve(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	defer svr.Close()
+
+	time.Sleep(100 * time.Millisecond)
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+
+	if signal := <-signalCh; signal != syscall.SIGHUP {
+		t.Fatalf("got %+v != want %+v", signal, syscall.SIGHUP)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if reloaded.Load() != 1 {
+		t.Fatalf("got %d != want 1", reloaded.Load())
+	}
+
+	if err := svr.(*server).ctx.Err(); err != nil {
+		t.Fatalf("server is closed after reloading: %+v", err)
+	}
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+
+	if signal := <-signalCh; signal != syscall.SIGUSR1 {
+		t.Fatalf("got %+v != want %+v", signal, syscall.SIGUSR1)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if err := svr.(*server).ctx.Err(); err == nil {
+		t.Fatal("server isn't closed after receiving signal")
+	}
+}
+
+// go test -v -cover -run=^TestServerMaxConns$
+func TestServerMaxConns(t *testing.T) {
+	handler := HandlerFunc(func(ctx *Context, data []byte) ([]byte, error) {
+		return data, nil
+	})
+
+	testCases := []struct {
+		name    string
+		opts    []Option
+		wantErr error
+	}{
+		{name: "reject", opts: []Option{WithMaxConns(1, ConnLimitReject)}, wantErr: errTooManyConns},
+		{name: "per ip", opts: []Option{WithMaxConnsPerIP(1)}, wantErr: errTooManyConnsPerIP},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			svr := NewServer("127.0.0.1:0", handler, testCase.opts...)
+
+			go func() {
+				if err := svr.Serve(); err != nil {
+					t.Error(err)
+				}
+			}()
+
+			defer svr.Close()
+
+			time.Sleep(100 * time.Millisecond)
+			address := svr.(*server).listener.Addr().String()
+
+			client, err := NewClient(address)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err = client.Send(context.Background(), nil); err != nil {
+				t.Fatal(err)
+			}
+
+			rejected, err := NewClient(address)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer rejected.Close()
+
+			time.Sleep(50 * time.Millisecond)
+
+			_, err = rejected.Send(context.Background(), nil)
+			if !errors.Is(err, ErrUnavailable) || err.Error() != testCase.wantErr.Error() {
+				t.Fatalf("got %+v != want %+v", err, testCase.wantErr)
+			}
+
+			if err = client.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			time.Sleep(50 * time.Millisecond)
+
+			client, err = NewClient(address)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer client.Close()
+
+			if _, err = client.Send(context.Background(), nil); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+// go test -v -cover -run=^TestServerMaxConnsBlock$
+func TestServerMaxConnsBlock(t *testing.T) {
+	handler := HandlerFunc(func(ctx *Context, data []byte) ([]byte, error) {
+		return data, nil
+	})
+
+	svr := NewServer("127.0.0.1:0", handler, WithMaxConns(1, ConnLimitBlock))
+
+	go func() {
+		if err := svr.Serve(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	defer svr.Close()
+
+	time.Sleep(100 * time.Millisecond)
+	address := svr.(*server).listener.Addr().String()
+
+	client, err := NewClient(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = client.Send(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	blocked, err := NewClient(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer blocked.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if _, err = blocked.Send(ctx, nil); err != context.DeadlineExceeded {
+		t.Fatalf("got %+v != want %+v", err, context.DeadlineExceeded)
+	}
+
+	if err = client.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []byte("unblocked")
+
+	got, err := blocked.Send(context.Background(), want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != string(want) {
+		t.Fatalf("got %s != want %s", got, want)
+	}
+}
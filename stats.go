@@ -0,0 +1,89 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vex
+
+import (
+	"slices"
+	"time"
+
+	histograms "github.com/FishGoddess/vex/internal/histogram"
+)
+
+// latencyBuckets are the upper bounds of latency histogram in client stats.
+var latencyBuckets = []time.Duration{
+	time.Millisecond, 5 * time.Millisecond, 10 * time.Millisecond, 50 * time.Millisecond,
+	100 * time.Millisecond, 500 * time.Millisecond, time.Second, 5 * time.Second,
+}
+
+// LatencyHistogram is the histogram of request latencies.
+type LatencyHistogram struct {
+	// Buckets are the upper bounds of buckets.
+	Buckets []time.Duration
+
+	// Counts are the cumulative counts of buckets, which means Counts[i] is the count of latencies <= Buckets[i].
+	Counts []uint64
+
+	// Count is the count of all latencies and Sum is the sum of them.
+	Count uint64
+	Sum   time.Duration
+}
+
+// ClientStats is the statistics of client.
+type ClientStats struct {
+	// Inflight is the number of requests waiting for responses.
+	Inflight uint64
+
+	// Sent is the number of requests sent.
+	Sent uint64
+
+	// Errors is the number of failed requests including timeouts.
+	Errors uint64
+
+	// Timeouts is the number of requests failed because of timeout.
+	Timeouts uint64
+
+	// BytesRead and BytesWritten are the bytes read from and written to connections.
+	BytesRead    uint64
+	BytesWritten uint64
+
+	// Latency is the histogram of request latencies.
+	Latency LatencyHistogram
+}
+
+// merge merges other stats to stats and returns the merged one.
+func (cs ClientStats) merge(other ClientStats) ClientStats {
+	cs.Inflight += other.Inflight
+	cs.Sent += other.Sent
+	cs.Errors += other.Errors
+	cs.Timeouts += other.Timeouts
+	cs.BytesRead += other.BytesRead
+	cs.BytesWritten += other.BytesWritten
+
+	if cs.Latency.Buckets == nil {
+		cs.Latency.Buckets = other.Latency.Buckets
+		cs.Latency.Counts = make([]uint64, len(other.Latency.Counts))
+	} else {
+		cs.Latency.Counts = slices.Clone(cs.Latency.Counts)
+	}
+
+	for i, count := range other.Latency.Counts {
+		cs.Latency.Counts[i] += count
+	}
+
+	cs.Latency.Count += other.Latency.Count
+	cs.Latency.Sum += other.Latency.Sum
+	return cs
+}
+
+// newLatencyHistogram returns a histogram recording the latencies of requests.
+func newLatencyHistogram() *histograms.Histogram[time.Duration] {
+	return histograms.New(latencyBuckets)
+}
+
+// latencySnapshot returns the snapshot of latency histogram.
+func latencySnapshot(histogram *histograms.Histogram[time.Duration]) LatencyHistogram {
+	snapshot := histogram.Snapshot()
+	return LatencyHistogram(snapshot)
+}
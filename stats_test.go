@@ -0,0 +1,67 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vex
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// go test -v -cover -run=^TestLatencyHistogram$
+func TestLatencyHistogram(t *testing.T) {
+	histogram := newLatencyHistogram()
+
+	snapshot := latencySnapshot(histogram)
+	if len(snapshot.Counts) != len(latencyBuckets) || snapshot.Count != 0 {
+		t.Fatalf("got %+v is wrong", snapshot)
+	}
+
+	histogram.Observe(time.Millisecond)
+	histogram.Observe(30 * time.Millisecond)
+	histogram.Observe(time.Minute)
+
+	snapshot = latencySnapshot(histogram)
+
+	want := []uint64{1, 1, 1, 2, 2, 2, 2, 2}
+	if !slices.Equal(snapshot.Counts, want) {
+		t.Fatalf("got %+v != want %+v", snapshot.Counts, want)
+	}
+
+	if snapshot.Count != 3 {
+		t.Fatalf("got %d != want 3", snapshot.Count)
+	}
+
+	if snapshot.Sum != time.Minute+31*time.Millisecond {
+		t.Fatalf("got %s != want %s", snapshot.Sum, time.Minute+31*time.Millisecond)
+	}
+}
+
+// go test -v -cover -run=^TestClientStatsMerge$
+func TestClientStatsMerge(t *testing.T) {
+	histogram := newLatencyHistogram()
+	histogram.Observe(time.Millisecond)
+
+	stats := ClientStats{Inflight: 1, Sent: 2, Errors: 3, Timeouts: 4, BytesRead: 5, BytesWritten: 6, Latency: latencySnapshot(histogram)}
+
+	var merged ClientStats
+	merged = merged.merge(stats)
+	merged = merged.merge(stats)
+
+	want := ClientStats{Inflight: 2, Sent: 4, Errors: 6, Timeouts: 8, BytesRead: 10, BytesWritten: 12}
+	if merged.Inflight != want.Inflight || merged.Sent != want.Sent || merged.Errors != want.Errors ||
+		merged.Timeouts != want.Timeouts || merged.BytesRead != want.BytesRead || merged.BytesWritten != want.BytesWritten {
+		t.Fatalf("got %+v != want %+v", merged, want)
+	}
+
+	if merged.Latency.Count != 2 || merged.Latency.Counts[0] != 2 || merged.Latency.Sum != 2*time.Millisecond {
+		t.Fatalf("got %+v is wrong", merged.Latency)
+	}
+
+	// Merging shouldn't modify the stats merged.
+	if stats.Latency.Counts[0] != 1 {
+		t.Fatalf("got %d != want 1", stats.Latency.Counts[0])
+	}
+}
@@ -0,0 +1,738 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vex
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"maps"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	packets "github.com/FishGoddess/vex/internal/packet"
+)
+
+// streamWindowBytes is the initial flow control window of streams in bytes.
+// The receiver can grow it by sending a window packet, so a larger window can be used without negotiating.
+const streamWindowBytes = 64 * 1024
+
+var (
+	errStreamNotSupported = NewError(CodeMethodNotFound, "vex: stream isn't supported")
+	errStreamSendClosed   = errors.New("vex: stream send is closed")
+	errStreamOverflowed   = NewError(CodeBadRequest, "vex: stream window is overflowed")
+)
+
+// StreamSender sends data to the stream of client.
+type StreamSender interface {
+	Send(data []byte) error
+}
+
+// ServerStream is a bidirectional stream in server.
+// Recv returns io.EOF if the client closes its sending side.
+type ServerStream interface {
+	StreamSender
+	Recv() ([]byte, error)
+}
+
+// Stream is a bidirectional stream in client.
+// Recv returns io.EOF if the server finishes the stream, and CloseSend closes the sending side only.
+// Close should be called if the stream isn't received until the end so the server can stop it.
+// Send and Recv can be called in different goroutines, but neither of them is safe to be called concurrently.
+type Stream interface {
+	Send(data []byte) error
+	Recv() ([]byte, error)
+	CloseSend() error
+	Close() error
+}
+
+// StreamHandler is for handling the data from client and sending a stream of data back.
+// The stream ends when it returns, and the error returned will be sent to client as the last one.
+// Server interceptors will be called with the data of open packet before handling the stream.
+type StreamHandler interface {
+	HandleStream(ctx *Context, data []byte, stream StreamSender) error
+}
+
+// StreamHandlerFunc is a function implementing StreamHandler.
+type StreamHandlerFunc func(ctx *Context, data []byte, stream StreamSender) error
+
+// HandleStream handles the data by calling the function itself.
+func (shf StreamHandlerFunc) HandleStream(ctx *Context, data []byte, stream StreamSender) error {
+	return shf(ctx, data, stream)
+}
+
+// BidiStreamHandler is for handling a bidirectional stream opened by client.
+// The stream ends when it returns, and the error returned will be sent to client as the last one.
+// Server interceptors will be called with the data of open packet before handling the stream.
+type BidiStreamHandler interface {
+	HandleBidiStream(ctx *Context, stream ServerStream) error
+}
+
+// BidiStreamHandlerFunc is a function implementing BidiStreamHandler.
+type BidiStreamHandlerFunc func(ctx *Context, stream ServerStream) error
+
+// HandleBidiStream handles the stream by calling the function itself.
+func (bshf BidiStreamHandlerFunc) HandleBidiStream(ctx *Context, stream ServerStream) error {
+	return bshf(ctx, stream)
+}
+
+// streamWindow is the flow control window of sending data to a stream.
+type streamWindow struct {
+	size int64
+
+	// grown is closed and replaced when the window grows so all waiters will be notified.
+	grown chan struct{}
+	lock  sync.Mutex
+}
+
+func newStreamWindow() *streamWindow {
+	window := &streamWindow{
+		size:  streamWindowBytes,
+		grown: make(chan struct{}),
+	}
+
+	return window
+}
+
+// take takes n bytes from window or returns a channel to wait if the window isn't enough.
+// Data larger than the initial window can be taken if the window is at least the initial size.
+func (w *streamWindow) take(n int64) (bool, <-chan struct{}) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.size >= n || w.size >= streamWindowBytes {
+		w.size -= n
+		return true, nil
+	}
+
+	return false, w.grown
+}
+
+func (w *streamWindow) grow(n int64) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.size += n
+	close(w.grown)
+	w.grown = make(chan struct{})
+}
+
+// streamQueue queues the packets received by a stream without blocking the reading of conn.
+// The number of bytes queued is limited by the flow control window checked by streamReceiver.
+type streamQueue struct {
+	packets []packets.Packet
+	pushed  chan struct{}
+	lock    sync.Mutex
+}
+
+func newStreamQueue() *streamQueue {
+	queue := &streamQueue{
+		pushed: make(chan struct{}, 1),
+	}
+
+	return queue
+}
+
+func (q *streamQueue) push(packet packets.Packet) {
+	q.lock.Lock()
+	q.packets = append(q.packets, packet)
+	q.lock.Unlock()
+
+	select {
+	case q.pushed <- struct{}{}:
+	default:
+	}
+}
+
+// pop pops a packet or returns a channel to wait if the queue is empty.
+func (q *streamQueue) pop() (packets.Packet, bool, <-chan struct{}) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if len(q.packets) == 0 {
+		return packets.Packet{}, false, q.pushed
+	}
+
+	packet := q.packets[0]
+	q.packets[0] = packets.Packet{}
+	q.packets = q.packets[1:]
+	return packet, true, nil
+}
+
+// last returns the last packet queued without popping it.
+func (q *streamQueue) last() (packets.Packet, bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if len(q.packets) == 0 {
+		return packets.Packet{}, false
+	}
+
+	return q.packets[len(q.packets)-1], true
+}
+
+// streamEnded returns if the packet sent by server ends the stream.
+// A packet without stream flag means the server responds it as a normal request, like rejecting it.
+func streamEnded(packet packets.Packet) bool {
+	return packet.IsEndOfStream() || !packet.IsStream()
+}
+
+// streamReceiver returns the consumed bytes to sender by window packets.
+type streamReceiver struct {
+	window   int64
+	consumed int64
+	send     func(packet packets.Packet) error
+
+	// received is the bytes received but not returned to sender yet.
+	received atomic.Int64
+}
+
+// receive records the bytes received and returns false if the sender overflows the window.
+// Data larger than the initial window is allowed if the window left is at least the initial size, like sender does.
+func (sr *streamReceiver) receive(n int) bool {
+	received := sr.received.Load()
+	if received+min(int64(n), streamWindowBytes) > sr.window {
+		return false
+	}
+
+	sr.received.Add(int64(n))
+	return true
+}
+
+// receivedBytes returns the bytes of data packet received which will be consumed later.
+func receivedBytes(packet packets.Packet) int {
+	if packet.IsEndOfStream() {
+		return 0
+	}
+
+	data, err := packet.Data()
+	if err != nil {
+		return 0
+	}
+
+	return len(data)
+}
+
+// growWindow sends the window of stream to sender if it's larger than the initial one.
+func (sr *streamReceiver) growWindow(id uint64) error {
+	if sr.window <= streamWindowBytes {
+		return nil
+	}
+
+	packet := packets.New(id)
+	packet.SetStream()
+	packet.SetWindow(uint32(sr.window - streamWindowBytes))
+	return sr.send(packet)
+}
+
+// consume records the bytes consumed and returns them to sender if there are enough bytes.
+func (sr *streamReceiver) consume(id uint64, n int) error {
+	sr.consumed += int64(n)
+	if sr.consumed < sr.window/2 {
+		return nil
+	}
+
+	packet := packets.New(id)
+	packet.SetStream()
+	packet.SetWindow(uint32(sr.consumed))
+
+	// Return the bytes before sending so the data sent after receiving the window won't overflow.
+	sr.received.Add(-sr.consumed)
+	sr.consumed = 0
+	return sr.send(packet)
+}
+
+type serverStream struct {
+	sc       *serverConn
+	ctx      *Context
+	id       uint64
+	window   *streamWindow
+	queue    *streamQueue
+	receiver *streamReceiver
+	received bool
+
+	// overflowed is closed if the client sends more data than the window.
+	overflowed     chan struct{}
+	overflowedOnce sync.Once
+}
+
+func newServerStream(sc *serverConn, id uint64) *serverStream {
+	ss := &serverStream{
+		sc:         sc,
+		id:         id,
+		window:     newStreamWindow(),
+		queue:      newStreamQueue(),
+		receiver:   &streamReceiver{window: int64(sc.server.conf.streamWindow), send: sc.writePacket},
+		overflowed: make(chan struct{}),
+	}
+
+	return ss
+}
+
+// Send sends data to client and blocks if the flow control window of stream is used up.
+// Returns an error if the request is canceled or the conn is closed.
+func (ss *serverStream) Send(data []byte) error {
+	for {
+		if err := ss.ctx.Err(); err != nil {
+			return err
+		}
+
+		ok, grown := ss.window.take(int64(len(data)))
+		if ok {
+			break
+		}
+
+		select {
+		case <-grown:
+		case <-ss.overflowed:
+			return errStreamOverflowed
+		case <-ss.ctx.Done():
+			return ss.ctx.Err()
+		case <-ss.sc.done:
+			return errConnectionLost
+		}
+	}
+
+	packet := packets.New(ss.id)
+	packet.SetStream()
+	packet.SetContentType(uint8(ss.ctx.responseContentType))
+	packet.SetData(data)
+	return ss.sc.writePacket(packet)
+}
+
+// Recv receives data from client and returns io.EOF if the client closes its sending side.
+func (ss *serverStream) Recv() ([]byte, error) {
+	if ss.received {
+		return nil, io.EOF
+	}
+
+	for {
+		if err := ss.ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		packet, ok, pushed := ss.queue.pop()
+		if !ok {
+			select {
+			case <-pushed:
+				continue
+			case <-ss.overflowed:
+				return nil, errStreamOverflowed
+			case <-ss.ctx.Done():
+				return nil, ss.ctx.Err()
+			case <-ss.sc.done:
+				return nil, errConnectionLost
+			}
+		}
+
+		if packet.IsEndOfStream() {
+			ss.received = true
+			return nil, io.EOF
+		}
+
+		data, err := packet.Data()
+		if err != nil {
+			return nil, err
+		}
+
+		if err = ss.receiver.consume(ss.id, len(data)); err != nil {
+			return nil, err
+		}
+
+		return data, nil
+	}
+}
+
+// dispatch dispatches the packet sent by client to stream.
+// The stream will be failed and the packets will be dropped if the client overflows the window.
+func (ss *serverStream) dispatch(packet packets.Packet) {
+	if packet.IsWindow() {
+		ss.window.grow(int64(packet.Window()))
+		return
+	}
+
+	select {
+	case <-ss.overflowed:
+		return
+	default:
+	}
+
+	if !ss.receiver.receive(receivedBytes(packet)) {
+		logger := ss.sc.server.conf.logger
+		logger.Error("stream window is overflowed", "id", ss.id)
+
+		ss.overflowedOnce.Do(func() { close(ss.overflowed) })
+		return
+	}
+
+	ss.queue.push(packet)
+}
+
+type clientStream struct {
+	client   *client
+	ctx      context.Context
+	id       uint64
+	begin    time.Time
+	window   *streamWindow
+	queue    *streamQueue
+	receiver *streamReceiver
+
+	// received is set after receiving the end of stream and only used by Recv.
+	received bool
+
+	// sendClosed is set after closing the sending side and only used by Send and CloseSend.
+	sendClosed bool
+
+	// failed is closed with failedErr if the stream is failed by client, like going away.
+	failed     chan struct{}
+	failedErr  error
+	failedOnce sync.Once
+
+	// ended is closed when the server ends the stream so sending to it won't be blocked by the window.
+	ended     chan struct{}
+	endedOnce sync.Once
+
+	// done is closed when the stream is finished.
+	done       chan struct{}
+	finishOnce sync.Once
+}
+
+func newClientStream(client *client, ctx context.Context, id uint64) *clientStream {
+	cs := &clientStream{
+		client: client,
+		ctx:    ctx,
+		id:     id,
+		window: newStreamWindow(),
+		queue:  newStreamQueue(),
+		failed: make(chan struct{}),
+		ended:  make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	writePacket := func(packet packets.Packet) error {
+		return packets.WritePacket(client.writer, packet)
+	}
+
+	cs.receiver = &streamReceiver{window: int64(client.conf.streamWindow), send: writePacket}
+	return cs
+}
+
+// dispatch dispatches the packet sent by server to stream without blocking.
+// The stream will be failed and the packets will be dropped if the server overflows the window.
+func (cs *clientStream) dispatch(packet packets.Packet) {
+	if packet.IsWindow() {
+		cs.window.grow(int64(packet.Window()))
+		return
+	}
+
+	select {
+	case <-cs.failed:
+		return
+	default:
+	}
+
+	if !cs.receiver.receive(receivedBytes(packet)) {
+		logger := cs.client.conf.logger
+		logger.Error("stream window is overflowed", "id", cs.id)
+
+		cs.fail(errStreamOverflowed)
+		return
+	}
+
+	cs.queue.push(packet)
+
+	if streamEnded(packet) {
+		cs.endedOnce.Do(func() { close(cs.ended) })
+	}
+}
+
+// fail fails the stream with err if it hasn't failed.
+func (cs *clientStream) fail(err error) {
+	cs.failedOnce.Do(func() {
+		cs.failedErr = err
+		close(cs.failed)
+	})
+}
+
+// finish finishes the stream and records its latency and error.
+func (cs *clientStream) finish(err error) {
+	cs.finishOnce.Do(func() {
+		close(cs.done)
+
+		cs.client.record(time.Since(cs.begin), err)
+		cs.client.removeInflight(cs.id)
+	})
+}
+
+// abort finishes the stream with err and tells server to cancel it if it isn't received until the end.
+func (cs *clientStream) abort(err error) {
+	if !cs.received {
+		cs.client.cancelRequest(cs.id)
+	}
+
+	cs.finish(err)
+}
+
+// end records the packet ending the stream and returns the error sent by server.
+func (cs *clientStream) end(packet packets.Packet) error {
+	cs.received = true
+
+	if contentType := responseContentTypeFromContext(cs.ctx); contentType != nil {
+		*contentType = ContentType(packet.ContentType())
+	}
+
+	if metadata := responseMetadataFromContext(cs.ctx); metadata != nil {
+		maps.Copy(metadata, packet.Metadata())
+	}
+
+	_, err := packetData(&packet)
+	return err
+}
+
+// ctxErr returns the error of context or the error sent by server if it's queued.
+// The error sent by server is more accurate than the context error, like rejecting the stream.
+func (cs *clientStream) ctxErr() error {
+	err := cs.ctx.Err()
+	if err == nil {
+		return nil
+	}
+
+	packet, ok := cs.queue.last()
+	if !ok || !streamEnded(packet) {
+		return err
+	}
+
+	// The timeout and cancellation of server are caused by the context, so the context error is returned.
+	_, packetErr := packetData(&packet)
+	if code := CodeOf(packetErr); packetErr == nil || code == CodeTimeout || code == CodeCanceled {
+		return err
+	}
+
+	return cs.end(packet)
+}
+
+func (cs *clientStream) recv() ([]byte, error) {
+	for {
+		// Check the context first since there may be lots of packets queued.
+		if err := cs.ctxErr(); err != nil {
+			return nil, err
+		}
+
+		packet, ok, pushed := cs.queue.pop()
+		if !ok {
+			select {
+			case <-pushed:
+				continue
+			case <-cs.failed:
+				return nil, cs.failedErr
+			case <-cs.ctx.Done():
+				continue
+			case <-cs.client.ctx.Done():
+				return nil, context.Cause(cs.client.ctx)
+			}
+		}
+
+		if streamEnded(packet) {
+			if err := cs.end(packet); err != nil {
+				return nil, err
+			}
+		} else if contentType := responseContentTypeFromContext(cs.ctx); contentType != nil {
+			*contentType = ContentType(packet.ContentType())
+		}
+
+		data, err := packetData(&packet)
+		if err != nil {
+			return nil, err
+		}
+
+		if packet.IsEndOfStream() {
+			return nil, io.EOF
+		}
+
+		if err = cs.receiver.consume(cs.id, len(data)); err != nil {
+			return nil, err
+		}
+
+		return data, nil
+	}
+}
+
+// Recv receives data from server and returns io.EOF if the server finishes the stream.
+// The stream is finished after returning an error.
+func (cs *clientStream) Recv() ([]byte, error) {
+	select {
+	case <-cs.done:
+		return nil, io.EOF
+	default:
+	}
+
+	if cs.received {
+		cs.finish(nil)
+		return nil, io.EOF
+	}
+
+	data, err := cs.recv()
+	if err == io.EOF {
+		cs.finish(nil)
+		return nil, io.EOF
+	}
+
+	if err != nil {
+		cs.abort(err)
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// Send sends data to server and blocks if the flow control window of stream is used up.
+// Returns io.EOF if the stream is finished or ended by server, and Recv can be used to get the error.
+func (cs *clientStream) Send(data []byte) error {
+	if cs.sendClosed {
+		return errStreamSendClosed
+	}
+
+	for {
+		ok, grown := cs.window.take(int64(len(data)))
+		if ok {
+			break
+		}
+
+		select {
+		case <-grown:
+		case <-cs.done:
+			return io.EOF
+		case <-cs.failed:
+			return io.EOF
+		case <-cs.ended:
+			return io.EOF
+		case <-cs.ctx.Done():
+			return cs.ctx.Err()
+		case <-cs.client.ctx.Done():
+			return context.Cause(cs.client.ctx)
+		}
+	}
+
+	select {
+	case <-cs.done:
+		return io.EOF
+	case <-cs.ended:
+		return io.EOF
+	default:
+	}
+
+	packet := packets.New(cs.id)
+	packet.SetStream()
+	packet.SetContentType(uint8(contentTypeFromContext(cs.ctx)))
+	packet.SetData(data)
+	return packets.WritePacket(cs.client.writer, packet)
+}
+
+// CloseSend closes the sending side of stream so the server will receive io.EOF.
+func (cs *clientStream) CloseSend() error {
+	if cs.sendClosed {
+		return nil
+	}
+
+	cs.sendClosed = true
+
+	packet := packets.New(cs.id)
+	packet.SetStream()
+	packet.SetEndOfStream()
+	return packets.WritePacket(cs.client.writer, packet)
+}
+
+// Close finishes the stream and tells server to cancel it if it isn't finished.
+func (cs *clientStream) Close() error {
+	select {
+	case <-cs.done:
+	default:
+		cs.abort(nil)
+	}
+
+	return nil
+}
+
+// openStream opens a stream and sends the open packet to server.
+// The sending side will be closed after sending data if closeSend is true.
+func (c *client) openStream(ctx context.Context, data []byte, closeSend bool) (*clientStream, error) {
+	timeout, err := requestTimeout(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.lockAvailable(); err != nil {
+		return nil, err
+	}
+
+	inflightID := c.nextInflightID()
+	cs := newClientStream(c, ctx, inflightID)
+	c.streams[inflightID] = cs
+	c.lock.Unlock()
+
+	c.sent.Add(1)
+	cs.begin = time.Now()
+
+	packet := newRequestPacket(ctx, inflightID, timeout, data)
+	packet.SetStream()
+	packet.SetOpenStream()
+
+	if closeSend {
+		packet.SetEndOfStream()
+		cs.sendClosed = true
+	}
+
+	if err = packets.WritePacket(c.writer, packet); err != nil {
+		cs.finish(err)
+		return nil, err
+	}
+
+	if err = cs.receiver.growWindow(inflightID); err != nil {
+		cs.abort(err)
+		return nil, err
+	}
+
+	return cs, nil
+}
+
+// OpenStream opens a bidirectional stream multiplexed on the connection.
+// The context controls the whole stream, and client interceptors won't be applied to it.
+func (c *client) OpenStream(ctx context.Context) (Stream, error) {
+	return c.openStream(ctx, nil, false)
+}
+
+// SendStream sends data and returns an iterator of the data streamed back by server.
+// The data will be sent again in each iteration, and the stream will be canceled if the iteration breaks.
+// The iteration stops after yielding an error.
+// Client interceptors won't be applied to streams.
+func (c *client) SendStream(ctx context.Context, data []byte) iter.Seq2[[]byte, error] {
+	return func(yield func([]byte, error) bool) {
+		cs, err := c.openStream(ctx, data, true)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		defer cs.Close()
+
+		for {
+			data, err := cs.Recv()
+			if err == io.EOF {
+				return
+			}
+
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			if !yield(data, nil) {
+				return
+			}
+		}
+	}
+}
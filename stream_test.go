@@ -0,0 +1,1085 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vex
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	packets "github.com/FishGoddess/vex/internal/packet"
+)
+
+func runTestStreamServer(t *testing.T, handler Handler, opts ...Option) (Server, string) {
+	svr := NewServer("127.0.0.1:0", handler, opts...)
+
+	go func() {
+		if err := svr.Serve(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+
+	address := svr.(*server).listener.Addr().String()
+	return svr, address
+}
+
+// go test -v -cover -run=^TestSendStream$
+func TestSendStream(t *testing.T) {
+	router := NewRouter()
+	router.RegisterStreamFunc("count", func(ctx *Context, data []byte, stream StreamSender) error {
+		n, err := strconv.Atoi(string(data))
+		if err != nil {
+			return ErrBadRequest
+		}
+
+		for i := range n {
+			if err = stream.Send([]byte(strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+
+		ctx.SetResponseMetadata("count", string(data))
+		return nil
+	})
+
+	svr, address := runTestStreamServer(t, router)
+	defer svr.Close()
+
+	client, err := NewClient(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	metadata := make(Metadata)
+	ctx := ContextWithMethod(context.Background(), "count")
+	ctx = ContextWithResponseMetadata(ctx, metadata)
+
+	var got []string
+	for data, err := range client.SendStream(ctx, []byte("100")) {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got = append(got, string(data))
+	}
+
+	if len(got) != 100 {
+		t.Fatalf("got %d != want %d", len(got), 100)
+	}
+
+	for i, data := range got {
+		if want := strconv.Itoa(i); data != want {
+			t.Fatalf("got %s != want %s", data, want)
+		}
+	}
+
+	if metadata["count"] != "100" {
+		t.Fatalf("got %s != want %s", metadata["count"], "100")
+	}
+
+	var errs []error
+	for _, err := range client.SendStream(ctx, []byte("wrong")) {
+		errs = append(errs, err)
+	}
+
+	if len(errs) != 1 || !errors.Is(errs[0], ErrBadRequest) {
+		t.Fatalf("got %+v is wrong", errs)
+	}
+
+	ctx = ContextWithMethod(context.Background(), "unknown")
+	for _, err := range client.SendStream(ctx, nil) {
+		if !errors.Is(err, ErrMethodNotFound) {
+			t.Fatalf("error %+v is not %+v", err, ErrMethodNotFound)
+		}
+	}
+
+	if stats := client.Stats(); stats.Inflight != 0 || stats.Sent != 3 || stats.Errors != 2 {
+		t.Fatalf("got %+v is wrong", stats)
+	}
+}
+
+// go test -v -cover -run=^TestSendStreamBreak$
+func TestSendStreamBreak(t *testing.T) {
+	canceled := make(chan error, 1)
+
+	router := NewRouter()
+	router.RegisterStreamFunc("infinite", func(ctx *Context, data []byte, stream StreamSender) error {
+		for i := 0; ; i++ {
+			if err := stream.Send([]byte(strconv.Itoa(i))); err != nil {
+				canceled <- err
+				return err
+			}
+		}
+	})
+
+	svr, address := runTestStreamServer(t, router)
+	defer svr.Close()
+
+	client, err := NewClient(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	ctx := ContextWithMethod(context.Background(), "infinite")
+
+	count := 0
+	for _, err := range client.SendStream(ctx, nil) {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if count++; count >= 10 {
+			break
+		}
+	}
+
+	select {
+	case err := <-canceled:
+		if err != context.Canceled {
+			t.Fatalf("got %+v != want %+v", err, context.Canceled)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("stream handler isn't canceled")
+	}
+
+	// The client should be still available after breaking a stream.
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+
+	count = 0
+	for _, err := range client.SendStream(ctx, nil) {
+		if err == context.DeadlineExceeded {
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Slow down receiving so lots of packets will be queued.
+		time.Sleep(time.Millisecond)
+		count++
+	}
+
+	if count == 0 {
+		t.Fatal("stream receives nothing before timeout")
+	}
+
+	if stats := client.Stats(); stats.Inflight != 0 || stats.Timeouts != 1 {
+		t.Fatalf("got %+v is wrong", stats)
+	}
+}
+
+// go test -v -cover -run=^TestSendStreamNotSupported$
+func TestSendStreamNotSupported(t *testing.T) {
+	handler := HandlerFunc(func(ctx *Context, data []byte) ([]byte, error) {
+		return data, nil
+	})
+
+	svr, address := runTestStreamServer(t, handler)
+	defer svr.Close()
+
+	client, err := NewClient(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, err := range client.SendStream(context.Background(), nil) {
+		if !errors.Is(err, errStreamNotSupported) {
+			t.Fatalf("error %+v is not %+v", err, errStreamNotSupported)
+		}
+	}
+
+	if err = client.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, err := range client.SendStream(context.Background(), nil) {
+		if err != errClientClosed {
+			t.Fatalf("got %+v != want %+v", err, errClientClosed)
+		}
+	}
+}
+
+// go test -v -cover -run=^TestSendStreamWrappedClients$
+func TestSendStreamWrappedClients(t *testing.T) {
+	router := NewRouter()
+	router.RegisterStreamFunc("repeat", func(ctx *Context, data []byte, stream StreamSender) error {
+		for range 3 {
+			if err := stream.Send(data); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	svr, address := runTestStreamServer(t, router)
+	defer svr.Close()
+
+	reconnectClient, err := NewClient(address, WithReconnect(10*time.Millisecond, 100*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer reconnectClient.Close()
+
+	pool := NewPool(1, func(ctx context.Context) (Client, error) {
+		return NewClient(address)
+	})
+
+	defer pool.Close()
+
+	ctx := ContextWithMethod(context.Background(), "repeat")
+
+	poolClient, err := pool.Get(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer poolClient.Close()
+
+	clients := map[string]Client{
+		"reconnect": reconnectClient,
+		"pool":      poolClient,
+	}
+
+	for name, client := range clients {
+		t.Run(name, func(t *testing.T) {
+			count := 0
+			for data, err := range client.SendStream(ctx, []byte(name)) {
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if string(data) != name {
+					t.Fatalf("got %s != want %s", data, name)
+				}
+
+				count++
+			}
+
+			if count != 3 {
+				t.Fatalf("got %d != want %d", count, 3)
+			}
+
+			// Breaking the iteration should stop the wrapped stream too.
+			for _, err := range client.SendStream(ctx, []byte(name)) {
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				break
+			}
+		})
+	}
+
+	reconnectClient.Close()
+
+	for _, err := range reconnectClient.SendStream(ctx, nil) {
+		if err != errClientClosed {
+			t.Fatalf("got %+v != want %+v", err, errClientClosed)
+		}
+	}
+}
+
+// go test -v -cover -run=^TestOpenStream$
+func TestOpenStream(t *testing.T) {
+	router := NewRouter()
+	router.RegisterBidiStreamFunc("echo", func(ctx *Context, stream ServerStream) error {
+		count := 0
+		for {
+			data, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+
+			if err != nil {
+				return err
+			}
+
+			if err = stream.Send(data); err != nil {
+				return err
+			}
+
+			count++
+		}
+
+		// The server can still send data after the client closes its sending side.
+		ctx.SetResponseMetadata("count", strconv.Itoa(count))
+		return stream.Send([]byte("bye"))
+	})
+
+	router.RegisterBidiStreamFunc("sum", func(ctx *Context, stream ServerStream) error {
+		sum := 0
+		for {
+			data, err := stream.Recv()
+			if err == io.EOF {
+				return stream.Send([]byte(strconv.Itoa(sum)))
+			}
+
+			if err != nil {
+				return err
+			}
+
+			n, err := strconv.Atoi(string(data))
+			if err != nil {
+				return ErrBadRequest
+			}
+
+			sum += n
+		}
+	})
+
+	svr, address := runTestStreamServer(t, router)
+	defer svr.Close()
+
+	client, err := NewClient(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	metadata := make(Metadata)
+	ctx := ContextWithMethod(context.Background(), "echo")
+	ctx = ContextWithResponseMetadata(ctx, metadata)
+
+	stream, err := client.OpenStream(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range 10 {
+		want := strconv.Itoa(i)
+		if err = stream.Send([]byte(want)); err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(data) != want {
+			t.Fatalf("got %s != want %s", data, want)
+		}
+	}
+
+	if err = stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = stream.Send(nil); err != errStreamSendClosed {
+		t.Fatalf("got %+v != want %+v", err, errStreamSendClosed)
+	}
+
+	data, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "bye" {
+		t.Fatalf("got %s != want %s", data, "bye")
+	}
+
+	if _, err = stream.Recv(); err != io.EOF {
+		t.Fatalf("got %+v != want %+v", err, io.EOF)
+	}
+
+	if metadata["count"] != "10" {
+		t.Fatalf("got %s != want %s", metadata["count"], "10")
+	}
+
+	if err = stream.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Client streaming: send lots of data and receive the result once.
+	ctx = ContextWithMethod(context.Background(), "sum")
+
+	stream, err = client.OpenStream(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer stream.Close()
+
+	for i := 1; i <= 100; i++ {
+		if err = stream.Send([]byte(strconv.Itoa(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stream.CloseSend()
+
+	if data, err = stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "5050" {
+		t.Fatalf("got %s != want %s", data, "5050")
+	}
+
+	if _, err = stream.Recv(); err != io.EOF {
+		t.Fatalf("got %+v != want %+v", err, io.EOF)
+	}
+
+	if stats := client.Stats(); stats.Inflight != 0 || stats.Sent != 2 || stats.Errors != 0 {
+		t.Fatalf("got %+v is wrong", stats)
+	}
+}
+
+// go test -v -cover -run=^TestOpenStreamError$
+func TestOpenStreamError(t *testing.T) {
+	canceled := make(chan error, 1)
+
+	router := NewRouter()
+	router.RegisterBidiStreamFunc("wait", func(ctx *Context, stream ServerStream) error {
+		_, err := stream.Recv()
+		canceled <- err
+		return err
+	})
+
+	router.RegisterBidiStreamFunc("fail", func(ctx *Context, stream ServerStream) error {
+		return ErrForbidden
+	})
+
+	svr, address := runTestStreamServer(t, router)
+	defer svr.Close()
+
+	client, err := NewClient(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	stream, err := client.OpenStream(ContextWithMethod(context.Background(), "wait"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Closing a stream which isn't finished will cancel it in server.
+	time.Sleep(20 * time.Millisecond)
+	stream.Close()
+
+	select {
+	case err := <-canceled:
+		if err != context.Canceled {
+			t.Fatalf("got %+v != want %+v", err, context.Canceled)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("stream handler isn't canceled")
+	}
+
+	if _, err = stream.Recv(); err != io.EOF {
+		t.Fatalf("got %+v != want %+v", err, io.EOF)
+	}
+
+	if err = stream.Send(nil); err != io.EOF {
+		t.Fatalf("got %+v != want %+v", err, io.EOF)
+	}
+
+	stream, err = client.OpenStream(ContextWithMethod(context.Background(), "fail"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = stream.Recv(); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("error %+v is not %+v", err, ErrForbidden)
+	}
+
+	// The stream is finished after receiving an error.
+	if _, err = stream.Recv(); err != io.EOF {
+		t.Fatalf("got %+v != want %+v", err, io.EOF)
+	}
+
+	ctx, cancel := context.WithTimeout(ContextWithMethod(context.Background(), "wait"), 50*time.Millisecond)
+	defer cancel()
+
+	if stream, err = client.OpenStream(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = stream.Recv(); err != context.DeadlineExceeded {
+		t.Fatalf("got %+v != want %+v", err, context.DeadlineExceeded)
+	}
+
+	<-canceled
+
+	if stats := client.Stats(); stats.Inflight != 0 || stats.Sent != 3 || stats.Errors != 2 || stats.Timeouts != 1 {
+		t.Fatalf("got %+v is wrong", stats)
+	}
+}
+
+// go test -v -cover -run=^TestOpenStreamNotSupported$
+func TestOpenStreamNotSupported(t *testing.T) {
+	handler := HandlerFunc(func(ctx *Context, data []byte) ([]byte, error) {
+		return data, nil
+	})
+
+	svr, address := runTestStreamServer(t, handler)
+	defer svr.Close()
+
+	client, err := NewClient(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	stream, err := client.OpenStream(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = stream.Recv(); !errors.Is(err, errStreamNotSupported) {
+		t.Fatalf("error %+v is not %+v", err, errStreamNotSupported)
+	}
+}
+
+// go test -v -cover -run=^TestOpenStreamRejected$
+func TestOpenStreamRejected(t *testing.T) {
+	router := NewRouter()
+	router.RegisterBidiStreamFunc("upload", func(ctx *Context, stream ServerStream) error {
+		return ErrUnauthorized
+	})
+
+	svr, address := runTestStreamServer(t, router)
+	defer svr.Close()
+
+	client, err := NewClient(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(ContextWithMethod(context.Background(), "upload"), 2*time.Second)
+	defer cancel()
+
+	stream, err := client.OpenStream(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer stream.Close()
+
+	// The sending should stop once the server rejects the stream instead of being blocked by the window.
+	chunk := bytes.Repeat([]byte("v"), 1024)
+	begin := time.Now()
+
+	for range 2 * streamWindowBytes / len(chunk) {
+		if err = stream.Send(chunk); err != nil {
+			break
+		}
+	}
+
+	if err != io.EOF {
+		t.Fatalf("got %+v != want %+v", err, io.EOF)
+	}
+
+	if cost := time.Since(begin); cost > time.Second {
+		t.Fatalf("sending to a rejected stream costs %s", cost)
+	}
+
+	if _, err = stream.Recv(); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("error %+v is not %+v", err, ErrUnauthorized)
+	}
+
+	ctx, cancel = context.WithTimeout(ContextWithMethod(context.Background(), "upload"), 50*time.Millisecond)
+	defer cancel()
+
+	if stream, err = client.OpenStream(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	defer stream.Close()
+
+	// The error sent by server should be returned even if the context is done after receiving it.
+	<-ctx.Done()
+
+	if _, err = stream.Recv(); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("error %+v is not %+v", err, ErrUnauthorized)
+	}
+}
+
+// go test -v -cover -run=^TestStreamServerInterceptors$
+func TestStreamServerInterceptors(t *testing.T) {
+	var handled atomic.Int64
+
+	router := NewRouter()
+	router.RegisterStreamFunc("echo", func(ctx *Context, data []byte, stream StreamSender) error {
+		handled.Add(1)
+		return stream.Send(data)
+	})
+
+	router.RegisterBidiStreamFunc("bidi_echo", func(ctx *Context, stream ServerStream) error {
+		handled.Add(1)
+
+		data, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		return stream.Send(data)
+	})
+
+	auth := func(ctx *Context, data []byte, handler Handler) ([]byte, error) {
+		if ctx.Metadata()["token"] != "secret" {
+			return nil, ErrUnauthorized
+		}
+
+		return handler.Handle(ctx, data)
+	}
+
+	svr, address := runTestStreamServer(t, router, WithServerInterceptors(auth))
+	defer svr.Close()
+
+	client, err := NewClient(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	ctx := ContextWithMethod(context.Background(), "echo")
+	for _, err := range client.SendStream(ctx, []byte("hello")) {
+		if !errors.Is(err, ErrUnauthorized) {
+			t.Fatalf("error %+v is not %+v", err, ErrUnauthorized)
+		}
+	}
+
+	bidiCtx := ContextWithMethod(context.Background(), "bidi_echo")
+
+	stream, err := client.OpenStream(bidiCtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = stream.Recv(); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("error %+v is not %+v", err, ErrUnauthorized)
+	}
+
+	if got := handled.Load(); got != 0 {
+		t.Fatalf("got %d != want 0", got)
+	}
+
+	ctx = ContextWithMetadata(ctx, Metadata{"token": "secret"})
+	for data, err := range client.SendStream(ctx, []byte("hello")) {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(data) != "hello" {
+			t.Fatalf("got %s != want %s", data, "hello")
+		}
+	}
+
+	bidiCtx = ContextWithMetadata(bidiCtx, Metadata{"token": "secret"})
+	if stream, err = client.OpenStream(bidiCtx); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = stream.Send([]byte("world")); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "world" {
+		t.Fatalf("got %s != want %s", data, "world")
+	}
+
+	if got := handled.Load(); got != 2 {
+		t.Fatalf("got %d != want 2", got)
+	}
+}
+
+// go test -v -cover -run=^TestStreamConnConcurrency$
+func TestStreamConnConcurrency(t *testing.T) {
+	router := NewRouter()
+	router.RegisterFunc("echo", func(ctx *Context, data []byte) ([]byte, error) {
+		return data, nil
+	})
+
+	router.RegisterBidiStreamFunc("bidi_echo", func(ctx *Context, stream ServerStream) error {
+		for {
+			data, err := stream.Recv()
+			if err == io.EOF {
+				return nil
+			}
+
+			if err != nil {
+				return err
+			}
+
+			if err = stream.Send(data); err != nil {
+				return err
+			}
+		}
+	})
+
+	svr, address := runTestStreamServer(t, router, WithConnConcurrency(1))
+	defer svr.Close()
+
+	client, err := NewClient(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	stream, err := client.OpenStream(ContextWithMethod(context.Background(), "bidi_echo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer stream.Close()
+
+	ctx, cancel := context.WithTimeout(ContextWithMethod(context.Background(), "echo"), time.Second)
+	defer cancel()
+
+	data, err := client.Send(ctx, []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "hello" {
+		t.Fatalf("got %s != want %s", data, "hello")
+	}
+
+	if err = stream.Send([]byte("world")); err != nil {
+		t.Fatal(err)
+	}
+
+	if data, err = stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "world" {
+		t.Fatalf("got %s != want %s", data, "world")
+	}
+}
+
+// go test -v -cover -run=^TestStreamFlowControl$
+func TestStreamFlowControl(t *testing.T) {
+	const chunkBytes = 1024
+	chunk := bytes.Repeat([]byte("v"), chunkBytes)
+
+	var sent atomic.Int64
+	var received atomic.Int64
+
+	router := NewRouter()
+	router.RegisterStreamFunc("download", func(ctx *Context, data []byte, stream StreamSender) error {
+		for range 200 {
+			if err := stream.Send(chunk); err != nil {
+				return err
+			}
+
+			sent.Add(1)
+		}
+
+		return nil
+	})
+
+	router.RegisterBidiStreamFunc("upload", func(ctx *Context, stream ServerStream) error {
+		// Receive nothing for a while so the client will be blocked on sending.
+		time.Sleep(200 * time.Millisecond)
+
+		for {
+			_, err := stream.Recv()
+			if err == io.EOF {
+				return nil
+			}
+
+			if err != nil {
+				return err
+			}
+
+			received.Add(1)
+		}
+	})
+
+	router.RegisterFunc("ping", func(ctx *Context, data []byte) ([]byte, error) {
+		return []byte("pong"), nil
+	})
+
+	svr, address := runTestStreamServer(t, router)
+	defer svr.Close()
+
+	testCases := map[string]struct {
+		window uint32
+		chunks int64
+	}{
+		"default": {window: 0, chunks: streamWindowBytes / chunkBytes},
+		"larger":  {window: 2 * streamWindowBytes, chunks: 2 * streamWindowBytes / chunkBytes},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			sent.Store(0)
+
+			client, err := NewClient(address, WithStreamWindow(testCase.window))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer client.Close()
+
+			ctx := ContextWithMethod(context.Background(), "download")
+
+			next, stop := iter.Pull2(client.SendStream(ctx, nil))
+			defer stop()
+
+			if _, err, _ = next(); err != nil {
+				t.Fatal(err)
+			}
+
+			// The server should be blocked by the window since the client receives nothing.
+			time.Sleep(100 * time.Millisecond)
+
+			if got := sent.Load(); got != testCase.chunks {
+				t.Fatalf("got %d != want %d", got, testCase.chunks)
+			}
+
+			// Other requests on the same connection shouldn't be starved by the blocked stream.
+			data, err := client.Send(ContextWithMethod(context.Background(), "ping"), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(data) != "pong" {
+				t.Fatalf("got %s != want %s", data, "pong")
+			}
+
+			count := 1
+			for {
+				_, err, ok := next()
+				if !ok {
+					break
+				}
+
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				count++
+			}
+
+			if count != 200 {
+				t.Fatalf("got %d != want %d", count, 200)
+			}
+		})
+	}
+
+	client, err := NewClient(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	stream, err := client.OpenStream(ContextWithMethod(context.Background(), "upload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer stream.Close()
+
+	var uploaded atomic.Int64
+	go func() {
+		for range 200 {
+			if err := stream.Send(chunk); err != nil {
+				t.Error(err)
+				return
+			}
+
+			uploaded.Add(1)
+		}
+
+		stream.CloseSend()
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+
+	if got := uploaded.Load(); got > streamWindowBytes/chunkBytes {
+		t.Fatalf("got %d > want %d", got, streamWindowBytes/chunkBytes)
+	}
+
+	if _, err = stream.Recv(); err != io.EOF {
+		t.Fatalf("got %+v != want %+v", err, io.EOF)
+	}
+
+	if got := received.Load(); got != 200 {
+		t.Fatalf("got %d != want %d", got, 200)
+	}
+}
+
+// go test -v -cover -run=^TestStreamOverflowed$
+func TestStreamOverflowed(t *testing.T) {
+	window := bytes.Repeat([]byte("v"), streamWindowBytes)
+
+	t.Run("server", func(t *testing.T) {
+		recvErr := make(chan error, 1)
+
+		router := NewRouter()
+		router.RegisterBidiStreamFunc("upload", func(ctx *Context, stream ServerStream) error {
+			// Receive nothing for a while so the data sent by client will be queued.
+			time.Sleep(100 * time.Millisecond)
+
+			for {
+				if _, err := stream.Recv(); err != nil {
+					recvErr <- err
+					return err
+				}
+			}
+		})
+
+		svr, address := runTestStreamServer(t, router)
+		defer svr.Close()
+
+		conn, err := net.Dial("tcp", address)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer conn.Close()
+
+		open := packets.New(1)
+		open.SetMethod("upload")
+		open.SetStream()
+		open.SetOpenStream()
+
+		if err = packets.WritePacket(conn, open); err != nil {
+			t.Fatal(err)
+		}
+
+		// The client sends more data than the window without waiting for it.
+		for _, data := range [][]byte{window, []byte("v")} {
+			packet := packets.New(1)
+			packet.SetStream()
+			packet.SetData(data)
+
+			if err = packets.WritePacket(conn, packet); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		for {
+			packet, err := packets.ReadPacket(conn)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if packet.IsWindow() {
+				continue
+			}
+
+			if code := Code(packet.ErrorCode()); code != CodeBadRequest {
+				t.Fatalf("got %d != want %d", code, CodeBadRequest)
+			}
+
+			break
+		}
+
+		if err = <-recvErr; err != errStreamOverflowed {
+			t.Fatalf("got %+v != want %+v", err, errStreamOverflowed)
+		}
+	})
+
+	t.Run("client", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer listener.Close()
+
+		canceled := make(chan bool, 1)
+		go func() {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			defer conn.Close()
+
+			open, err := packets.ReadPacket(conn)
+			if err != nil {
+				return
+			}
+
+			// The server sends more data than the window without waiting for it.
+			for _, data := range [][]byte{window, []byte("v")} {
+				packet := packets.New(open.ID())
+				packet.SetStream()
+				packet.SetData(data)
+				packets.WritePacket(conn, packet)
+			}
+
+			for {
+				packet, err := packets.ReadPacket(conn)
+				if err != nil {
+					canceled <- false
+					return
+				}
+
+				if packet.IsCancel() {
+					canceled <- packet.ID() == open.ID()
+					return
+				}
+			}
+		}()
+
+		client, err := NewClient(listener.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer client.Close()
+
+		stream, err := client.OpenStream(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer stream.Close()
+
+		data, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(data) != streamWindowBytes {
+			t.Fatalf("got %d != want %d", len(data), streamWindowBytes)
+		}
+
+		if _, err = stream.Recv(); err != errStreamOverflowed {
+			t.Fatalf("got %+v != want %+v", err, errStreamOverflowed)
+		}
+
+		if !<-canceled {
+			t.Fatal("stream isn't canceled")
+		}
+	})
+}
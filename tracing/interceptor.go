@@ -0,0 +1,93 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tracing
+
+import (
+	"context"
+
+	"github.com/FishGoddess/vex"
+)
+
+// InjectInterceptor returns a client interceptor injecting the span context in ctx into metadata.
+func InjectInterceptor() vex.ClientInterceptor {
+	return func(ctx context.Context, data []byte, send vex.SendFunc) ([]byte, error) {
+		ctx = Inject(ctx, SpanContextFromContext(ctx))
+		return send(ctx, data)
+	}
+}
+
+// ExtractInterceptor returns a server interceptor extracting the span context from metadata into vex.Context.
+// Use SpanContextFromContext to get the span context in handler.
+func ExtractInterceptor() vex.ServerInterceptor {
+	return func(ctx *vex.Context, data []byte, handler vex.Handler) ([]byte, error) {
+		sc, ok := Extract(ctx.Metadata())
+		if !ok {
+			return handler.Handle(ctx, data)
+		}
+
+		parent := ctx.Context
+		defer func() {
+			ctx.Context = parent
+		}()
+
+		ctx.Context = ContextWithSpanContext(parent, sc)
+		return handler.Handle(ctx, data)
+	}
+}
+
+// ClientSpanInterceptor returns a client interceptor starting a client span for each request.
+// The span context of started span will be injected into metadata.
+func ClientSpanInterceptor(tracer Tracer) vex.ClientInterceptor {
+	return func(ctx context.Context, data []byte, send vex.SendFunc) ([]byte, error) {
+		method := vex.MethodFromContext(ctx)
+
+		ctx, span := tracer.Start(ctx, method, SpanKindClient)
+		defer span.End()
+
+		span.SetAttribute("rpc.system", "vex")
+		span.SetAttribute("rpc.method", method)
+
+		ctx = Inject(ctx, span.SpanContext())
+
+		data, err := send(ctx, data)
+		if err != nil {
+			span.RecordError(err)
+		}
+
+		return data, err
+	}
+}
+
+// ServerSpanInterceptor returns a server interceptor starting a server span for each request.
+// The span will be the child of the span context extracted from metadata if it exists.
+func ServerSpanInterceptor(tracer Tracer) vex.ServerInterceptor {
+	return func(ctx *vex.Context, data []byte, handler vex.Handler) ([]byte, error) {
+		parent := ctx.Context
+		defer func() {
+			ctx.Context = parent
+		}()
+
+		spanCtx := parent
+		if sc, ok := Extract(ctx.Metadata()); ok {
+			spanCtx = ContextWithSpanContext(spanCtx, sc)
+		}
+
+		spanCtx, span := tracer.Start(spanCtx, ctx.Method(), SpanKindServer)
+		defer span.End()
+
+		span.SetAttribute("rpc.system", "vex")
+		span.SetAttribute("rpc.method", ctx.Method())
+		span.SetAttribute("net.peer.address", ctx.RemoteAddress())
+
+		ctx.Context = spanCtx
+
+		data, err := handler.Handle(ctx, data)
+		if err != nil {
+			span.RecordError(err)
+		}
+
+		return data, err
+	}
+}
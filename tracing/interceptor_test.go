@@ -0,0 +1,139 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tracing
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/FishGoddess/vex"
+)
+
+func runTestServer(t *testing.T, handler vex.Handler, opts ...vex.Option) (address string, done func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svr := vex.NewServer(listener.Addr().String(), handler, opts...)
+
+	go func() {
+		if err := svr.ServeListener(listener); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	return listener.Addr().String(), func() { svr.Close() }
+}
+
+// go test -v -cover -run=^TestPropagation$
+func TestPropagation(t *testing.T) {
+	serverSC := make(chan SpanContext, 1)
+	handler := vex.HandlerFunc(func(ctx *vex.Context, data []byte) ([]byte, error) {
+		serverSC <- SpanContextFromContext(ctx)
+		return data, nil
+	})
+
+	address, done := runTestServer(t, handler, vex.WithServerInterceptors(ExtractInterceptor()))
+	defer done()
+
+	client, err := vex.NewClient(address, vex.WithClientInterceptors(InjectInterceptor()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	sc, err := ParseTraceparent(testTraceparent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := ContextWithSpanContext(context.Background(), sc)
+	if _, err = client.Send(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	sc.Remote = true
+	if got := <-serverSC; got != sc {
+		t.Fatalf("got %+v != want %+v", got, sc)
+	}
+
+	// No span context means nothing to propagate.
+	if _, err = client.Send(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := <-serverSC; got.IsValid() {
+		t.Fatalf("got %+v is valid", got)
+	}
+}
+
+// go test -v -cover -run=^TestSpanInterceptors$
+func TestSpanInterceptors(t *testing.T) {
+	recorder := NewRecorder()
+
+	router := vex.NewRouter()
+	router.RegisterFunc("hello", func(ctx *vex.Context, data []byte) ([]byte, error) {
+		return data, nil
+	})
+
+	address, done := runTestServer(t, router, vex.WithServerInterceptors(ServerSpanInterceptor(recorder)))
+	defer done()
+
+	client, err := vex.NewClient(address, vex.WithClientInterceptors(ClientSpanInterceptor(recorder)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	ctx := vex.ContextWithMethod(context.Background(), "hello")
+	if _, err = client.Send(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx = vex.ContextWithMethod(context.Background(), "missing")
+	if _, err = client.Send(ctx, nil); err == nil {
+		t.Fatal("send missing method returns a nil error")
+	}
+
+	spans := recorder.Spans()
+	if len(spans) != 4 {
+		t.Fatalf("got %d != want 4", len(spans))
+	}
+
+	// The server span ends before the client span.
+	for i := 0; i < len(spans); i += 2 {
+		server, client := spans[i], spans[i+1]
+
+		if server.Kind != SpanKindServer || client.Kind != SpanKindClient {
+			t.Fatalf("got kinds (%s, %s) is wrong", server.Kind, client.Kind)
+		}
+
+		if server.Parent.SpanID != client.SpanContext.SpanID || !server.Parent.Remote {
+			t.Fatalf("server span %+v isn't the child of client span %+v", server, client)
+		}
+
+		if server.SpanContext.TraceID != client.SpanContext.TraceID {
+			t.Fatalf("got %s != want %s", server.SpanContext.TraceID, client.SpanContext.TraceID)
+		}
+
+		if server.Attributes["rpc.method"] != client.Name || server.Attributes["net.peer.address"] == "" {
+			t.Fatalf("got %+v is wrong", server.Attributes)
+		}
+	}
+
+	if spans[0].Err != nil || spans[1].Err != nil {
+		t.Fatalf("got errors (%+v, %+v) is wrong", spans[0].Err, spans[1].Err)
+	}
+
+	if spans[2].Err == nil || spans[3].Err == nil {
+		t.Fatal("missing method span has no error")
+	}
+}
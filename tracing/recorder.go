@@ -0,0 +1,145 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tracing
+
+import (
+	"context"
+	"encoding/binary"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+)
+
+// RecordedSpan is a span ended and recorded by recorder.
+type RecordedSpan struct {
+	Name        string
+	Kind        SpanKind
+	SpanContext SpanContext
+	Parent      SpanContext
+	Attributes  map[string]string
+	Err         error
+	StartTime   time.Time
+	EndTime     time.Time
+}
+
+// Recorder is a tracer recording spans in memory, which is useful in testing.
+type Recorder struct {
+	spans []RecordedSpan
+	lock  sync.Mutex
+}
+
+// NewRecorder returns a new recorder.
+func NewRecorder() *Recorder {
+	return new(Recorder)
+}
+
+func newTraceID() (id TraceID) {
+	binary.BigEndian.PutUint64(id[:8], rand.Uint64())
+	binary.BigEndian.PutUint64(id[8:], rand.Uint64())
+	return id
+}
+
+func newSpanID() (id SpanID) {
+	binary.BigEndian.PutUint64(id[:], rand.Uint64())
+	return id
+}
+
+// Start starts a span which is the child of the span context in ctx if it's valid.
+func (r *Recorder) Start(ctx context.Context, name string, kind SpanKind) (context.Context, Span) {
+	parent := SpanContextFromContext(ctx)
+
+	sc := SpanContext{SpanID: newSpanID(), TraceFlags: flagSampled}
+	if parent.IsValid() {
+		sc.TraceID = parent.TraceID
+		sc.TraceFlags = parent.TraceFlags
+		sc.TraceState = parent.TraceState
+	} else {
+		sc.TraceID = newTraceID()
+	}
+
+	span := &recordingSpan{
+		recorder: r,
+		span: RecordedSpan{
+			Name:        name,
+			Kind:        kind,
+			SpanContext: sc,
+			Parent:      parent,
+			Attributes:  make(map[string]string, 4),
+			StartTime:   time.Now(),
+		},
+	}
+
+	ctx = ContextWithSpanContext(ctx, sc)
+	return ctx, span
+}
+
+// Spans returns the spans recorded in the order of ending.
+func (r *Recorder) Spans() []RecordedSpan {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return slices.Clone(r.spans)
+}
+
+// Reset removes all recorded spans.
+func (r *Recorder) Reset() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.spans = nil
+}
+
+func (r *Recorder) record(span RecordedSpan) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.spans = append(r.spans, span)
+}
+
+type recordingSpan struct {
+	recorder *Recorder
+	span     RecordedSpan
+	ended    bool
+
+	lock sync.Mutex
+}
+
+func (rs *recordingSpan) SpanContext() SpanContext {
+	return rs.span.SpanContext
+}
+
+func (rs *recordingSpan) SetAttribute(key string, value string) {
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+
+	rs.span.Attributes[key] = value
+}
+
+func (rs *recordingSpan) RecordError(err error) {
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+
+	rs.span.Err = err
+}
+
+func (rs *recordingSpan) End() {
+	rs.lock.Lock()
+	if rs.ended {
+		rs.lock.Unlock()
+
+		return
+	}
+
+	rs.ended = true
+	rs.span.EndTime = time.Now()
+
+	span := rs.span
+	span.Attributes = maps.Clone(rs.span.Attributes)
+	rs.lock.Unlock()
+
+	rs.recorder.record(span)
+}
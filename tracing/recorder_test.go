@@ -0,0 +1,69 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// go test -v -cover -run=^TestRecorder$
+func TestRecorder(t *testing.T) {
+	recorder := NewRecorder()
+
+	ctx, root := recorder.Start(context.Background(), "root", SpanKindClient)
+	if !root.SpanContext().IsValid() || !root.SpanContext().IsSampled() {
+		t.Fatalf("got %+v is wrong", root.SpanContext())
+	}
+
+	if got := SpanContextFromContext(ctx); got != root.SpanContext() {
+		t.Fatalf("got %+v != want %+v", got, root.SpanContext())
+	}
+
+	_, child := recorder.Start(ctx, "child", SpanKindServer)
+	child.SetAttribute("key", "value")
+	child.RecordError(errors.New("child error"))
+	child.End()
+	child.End()
+	root.End()
+
+	spans := recorder.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("got %d != want 2", len(spans))
+	}
+
+	if spans[0].Name != "child" || spans[0].Kind != SpanKindServer || spans[0].Attributes["key"] != "value" || spans[0].Err == nil {
+		t.Fatalf("got %+v is wrong", spans[0])
+	}
+
+	if spans[0].SpanContext.TraceID != spans[1].SpanContext.TraceID || spans[0].Parent != spans[1].SpanContext {
+		t.Fatalf("got %+v isn't the child of %+v", spans[0], spans[1])
+	}
+
+	if spans[1].Parent.IsValid() {
+		t.Fatalf("got %+v has a parent", spans[1])
+	}
+
+	recorder.Reset()
+	if spans = recorder.Spans(); len(spans) != 0 {
+		t.Fatalf("got %d != want 0", len(spans))
+	}
+}
+
+// go test -v -cover -run=^TestSpanKind$
+func TestSpanKind(t *testing.T) {
+	testCases := map[SpanKind]string{
+		SpanKindClient: "client",
+		SpanKindServer: "server",
+		0:              "unknown",
+	}
+
+	for kind, want := range testCases {
+		if got := kind.String(); got != want {
+			t.Fatalf("got %s != want %s", got, want)
+		}
+	}
+}
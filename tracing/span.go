@@ -0,0 +1,51 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tracing
+
+import "context"
+
+// SpanKind is the kind of span.
+type SpanKind uint8
+
+const (
+	SpanKindClient SpanKind = iota + 1
+	SpanKindServer
+)
+
+// String returns the name of span kind.
+func (sk SpanKind) String() string {
+	switch sk {
+	case SpanKindClient:
+		return "client"
+	case SpanKindServer:
+		return "server"
+	default:
+		return "unknown"
+	}
+}
+
+// Span is a span started by tracer.
+// It's easy to adapt the span of OpenTelemetry to it.
+type Span interface {
+	// SpanContext returns the span context of span.
+	SpanContext() SpanContext
+
+	// SetAttribute sets an attribute to span.
+	SetAttribute(key string, value string)
+
+	// RecordError records the error to span.
+	RecordError(err error)
+
+	// End ends the span.
+	End()
+}
+
+// Tracer starts spans.
+// It's easy to adapt the tracer of OpenTelemetry to it.
+type Tracer interface {
+	// Start starts a span which is the child of the span context in ctx if it's valid.
+	// It returns a new context carrying the span context of the started span.
+	Start(ctx context.Context, name string, kind SpanKind) (context.Context, Span)
+}
@@ -0,0 +1,189 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"maps"
+
+	"github.com/FishGoddess/vex"
+)
+
+// These are the metadata keys carrying the span context in W3C trace context format.
+const (
+	// TraceparentKey is the metadata key of traceparent.
+	TraceparentKey = "traceparent"
+
+	// TracestateKey is the metadata key of tracestate.
+	TracestateKey = "tracestate"
+)
+
+const (
+	traceparentVersion = "00"
+	traceparentLength  = 55
+	flagSampled        = 0x01
+)
+
+var (
+	errWrongTraceparent = errors.New("vex: wrong traceparent")
+)
+
+// TraceID is the id of a trace.
+type TraceID [16]byte
+
+// IsValid returns if the trace id isn't all zeros.
+func (id TraceID) IsValid() bool {
+	return id != TraceID{}
+}
+
+// String returns the hex string of trace id.
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// SpanID is the id of a span.
+type SpanID [8]byte
+
+// IsValid returns if the span id isn't all zeros.
+func (id SpanID) IsValid() bool {
+	return id != SpanID{}
+}
+
+// String returns the hex string of span id.
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// SpanContext is the context of a span which will be propagated across processes.
+type SpanContext struct {
+	TraceID    TraceID
+	SpanID     SpanID
+	TraceFlags byte
+	TraceState string
+
+	// Remote reports whether the span context is extracted from a remote process.
+	Remote bool
+}
+
+// IsValid returns if both trace id and span id are valid.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// IsSampled returns if the sampled flag is set.
+func (sc SpanContext) IsSampled() bool {
+	return sc.TraceFlags&flagSampled != 0
+}
+
+// Traceparent returns the traceparent of span context.
+func (sc SpanContext) Traceparent() string {
+	flags := []byte{sc.TraceFlags}
+	return traceparentVersion + "-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + hex.EncodeToString(flags)
+}
+
+func decodeHex(dst []byte, src string) bool {
+	// Only lowercase hex is allowed in traceparent.
+	for _, c := range src {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+
+	n, err := hex.Decode(dst, []byte(src))
+	return err == nil && n == len(dst)
+}
+
+// ParseTraceparent parses the traceparent and returns the span context.
+// Versions other than 00 are parsed as 00 if they have the same prefix, as the specification suggests.
+func ParseTraceparent(traceparent string) (sc SpanContext, err error) {
+	if len(traceparent) < traceparentLength {
+		return sc, errWrongTraceparent
+	}
+
+	version := traceparent[:2]
+	if version == "ff" || !decodeHex(make([]byte, 1), version) {
+		return sc, errWrongTraceparent
+	}
+
+	if version == traceparentVersion && len(traceparent) != traceparentLength {
+		return sc, errWrongTraceparent
+	}
+
+	if len(traceparent) > traceparentLength && traceparent[traceparentLength] != '-' {
+		return sc, errWrongTraceparent
+	}
+
+	if traceparent[2] != '-' || traceparent[35] != '-' || traceparent[52] != '-' {
+		return sc, errWrongTraceparent
+	}
+
+	var flags [1]byte
+	if !decodeHex(sc.TraceID[:], traceparent[3:35]) || !decodeHex(sc.SpanID[:], traceparent[36:52]) || !decodeHex(flags[:], traceparent[53:55]) {
+		return SpanContext{}, errWrongTraceparent
+	}
+
+	sc.TraceFlags = flags[0]
+	if !sc.IsValid() {
+		return SpanContext{}, errWrongTraceparent
+	}
+
+	return sc, nil
+}
+
+type spanContextKey struct{}
+
+// ContextWithSpanContext returns a new context carrying the span context.
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// SpanContextFromContext returns the span context carried by context.
+// It works with vex.Context on server after extracting.
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	sc, _ := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc
+}
+
+// Inject returns a new context whose metadata carries the span context so it will be sent by client.
+// The metadata in ctx won't be modified because it's copied before injecting.
+func Inject(ctx context.Context, sc SpanContext) context.Context {
+	if !sc.IsValid() {
+		return ctx
+	}
+
+	metadata := maps.Clone(vex.MetadataFromContext(ctx))
+	if metadata == nil {
+		metadata = make(vex.Metadata, 2)
+	}
+
+	metadata[TraceparentKey] = sc.Traceparent()
+
+	if sc.TraceState != "" {
+		metadata[TracestateKey] = sc.TraceState
+	} else {
+		delete(metadata, TracestateKey)
+	}
+
+	return vex.ContextWithMetadata(ctx, metadata)
+}
+
+// Extract returns the span context carried by metadata and false if there isn't a valid one.
+func Extract(metadata vex.Metadata) (SpanContext, bool) {
+	traceparent, ok := metadata[TraceparentKey]
+	if !ok {
+		return SpanContext{}, false
+	}
+
+	sc, err := ParseTraceparent(traceparent)
+	if err != nil {
+		return SpanContext{}, false
+	}
+
+	sc.TraceState = metadata[TracestateKey]
+	sc.Remote = true
+	return sc, true
+}
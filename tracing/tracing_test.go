@@ -0,0 +1,123 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/FishGoddess/vex"
+)
+
+const testTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+// go test -v -cover -run=^TestParseTraceparent$
+func TestParseTraceparent(t *testing.T) {
+	sc, err := ParseTraceparent(testTraceparent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("got %s is wrong", sc.TraceID)
+	}
+
+	if sc.SpanID.String() != "00f067aa0ba902b7" {
+		t.Fatalf("got %s is wrong", sc.SpanID)
+	}
+
+	if !sc.IsSampled() {
+		t.Fatal("span context isn't sampled")
+	}
+
+	if got := sc.Traceparent(); got != testTraceparent {
+		t.Fatalf("got %s != want %s", got, testTraceparent)
+	}
+
+	// Future versions may append fields after flags.
+	if _, err = ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-future"); err != nil {
+		t.Fatal(err)
+	}
+
+	wrongs := []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00_4bf92f3577b34da6a3ce929d0e0e4736_00f067aa0ba902b7_01",
+		"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01future",
+	}
+
+	for _, wrong := range wrongs {
+		if _, err = ParseTraceparent(wrong); err != errWrongTraceparent {
+			t.Fatalf("traceparent %q got %+v != want %+v", wrong, err, errWrongTraceparent)
+		}
+	}
+}
+
+// go test -v -cover -run=^TestInjectExtract$
+func TestInjectExtract(t *testing.T) {
+	sc, err := ParseTraceparent(testTraceparent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sc.TraceState = "vex=1"
+
+	metadata := vex.Metadata{"key": "value"}
+	ctx := vex.ContextWithMetadata(context.Background(), metadata)
+	ctx = Inject(ctx, sc)
+
+	injected := vex.MetadataFromContext(ctx)
+	if injected[TraceparentKey] != testTraceparent || injected[TracestateKey] != "vex=1" || injected["key"] != "value" {
+		t.Fatalf("got %+v is wrong", injected)
+	}
+
+	if len(metadata) != 1 {
+		t.Fatalf("got %+v is modified", metadata)
+	}
+
+	extracted, ok := Extract(injected)
+	if !ok {
+		t.Fatal("extract failed")
+	}
+
+	sc.Remote = true
+	if extracted != sc {
+		t.Fatalf("got %+v != want %+v", extracted, sc)
+	}
+
+	if _, ok = Extract(vex.Metadata{TraceparentKey: "wrong"}); ok {
+		t.Fatal("extract wrong traceparent succeeded")
+	}
+
+	if _, ok = Extract(nil); ok {
+		t.Fatal("extract nil metadata succeeded")
+	}
+
+	if got := Inject(context.Background(), SpanContext{}); vex.MetadataFromContext(got) != nil {
+		t.Fatal("inject invalid span context")
+	}
+}
+
+// go test -v -cover -run=^TestSpanContextFromContext$
+func TestSpanContextFromContext(t *testing.T) {
+	sc, err := ParseTraceparent(testTraceparent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := SpanContextFromContext(context.Background()); got.IsValid() {
+		t.Fatalf("got %+v is valid", got)
+	}
+
+	ctx := ContextWithSpanContext(context.Background(), sc)
+	if got := SpanContextFromContext(ctx); got != sc {
+		t.Fatalf("got %+v != want %+v", got, sc)
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vex
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+)
+
+var (
+	errPipeRefused   = errors.New("vex: pipe connection refused")
+	errPipeListening = errors.New("vex: pipe address is already listening")
+)
+
+// Transport dials and listens on address, which decides the network used by client and server.
+type Transport interface {
+	Dial(ctx context.Context, address string) (net.Conn, error)
+	Listen(ctx context.Context, address string) (net.Listener, error)
+}
+
+type networkTransport struct {
+	network string
+}
+
+// NewTCPTransport returns a transport using tcp network which is the default transport.
+func NewTCPTransport() Transport {
+	return networkTransport{network: "tcp"}
+}
+
+// NewUnixTransport returns a transport using unix domain socket, and the address is the path of socket.
+func NewUnixTransport() Transport {
+	return networkTransport{network: "unix"}
+}
+
+// Dial dials the address and returns the conn.
+func (nt networkTransport) Dial(ctx context.Context, address string) (net.Conn, error) {
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, nt.network, address)
+}
+
+// Listen listens on the address and returns the listener.
+func (nt networkTransport) Listen(ctx context.Context, address string) (net.Listener, error) {
+	var lc net.ListenConfig
+	return lc.Listen(ctx, nt.network, address)
+}
+
+type pipeAddr string
+
+// Network returns the network of pipe address.
+func (pa pipeAddr) Network() string {
+	return "pipe"
+}
+
+// String returns the pipe address.
+func (pa pipeAddr) String() string {
+	return string(pa)
+}
+
+type pipeListener struct {
+	transport *PipeTransport
+
+	address string
+	conns   chan net.Conn
+	done    chan struct{}
+	once    sync.Once
+}
+
+// Accept waits for and returns the next conn dialed to listener.
+func (pl *pipeListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-pl.conns:
+		return conn, nil
+	case <-pl.done:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close closes the listener and the address can be listened again.
+func (pl *pipeListener) Close() error {
+	pl.once.Do(func() {
+		close(pl.done)
+
+		pl.transport.lock.Lock()
+		delete(pl.transport.listeners, pl.address)
+		pl.transport.lock.Unlock()
+	})
+
+	return nil
+}
+
+// Addr returns the address of listener.
+func (pl *pipeListener) Addr() net.Addr {
+	return pipeAddr(pl.address)
+}
+
+// PipeTransport is an in-memory transport using net.Pipe, which is useful in tests without ports.
+// The client and server should use the same pipe transport.
+type PipeTransport struct {
+	listeners map[string]*pipeListener
+	lock      sync.Mutex
+}
+
+// NewPipeTransport returns a new in-memory transport.
+func NewPipeTransport() *PipeTransport {
+	transport := &PipeTransport{
+		listeners: make(map[string]*pipeListener, 4),
+	}
+
+	return transport
+}
+
+// Dial dials the address listened by the same transport and returns the conn.
+func (pt *PipeTransport) Dial(ctx context.Context, address string) (net.Conn, error) {
+	pt.lock.Lock()
+	listener := pt.listeners[address]
+	pt.lock.Unlock()
+
+	if listener == nil {
+		return nil, errPipeRefused
+	}
+
+	clientConn, serverConn := net.Pipe()
+
+	select {
+	case listener.conns <- serverConn:
+		return clientConn, nil
+	case <-listener.done:
+		clientConn.Close()
+		serverConn.Close()
+		return nil, errPipeRefused
+	case <-ctx.Done():
+		clientConn.Close()
+		serverConn.Close()
+		return nil, ctx.Err()
+	}
+}
+
+// Listen listens on the address and returns the listener.
+func (pt *PipeTransport) Listen(ctx context.Context, address string) (net.Listener, error) {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+
+	if _, ok := pt.listeners[address]; ok {
+		return nil, errPipeListening
+	}
+
+	listener := &pipeListener{
+		transport: pt,
+		address:   address,
+		conns:     make(chan net.Conn),
+		done:      make(chan struct{}),
+	}
+
+	pt.listeners[address] = listener
+	return listener, nil
+}
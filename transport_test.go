@@ -0,0 +1,143 @@
+// Copyright 2025 FishGoddess. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vex
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testTransport(t *testing.T, transport Transport, address string, opts ...Option) {
+	opts = append(opts, WithTransport(transport))
+	svr := NewServer(address, new(testHandler), opts...)
+
+	go func() {
+		if err := svr.Serve(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	defer svr.Close()
+
+	time.Sleep(100 * time.Millisecond)
+
+	client, err := NewClient(address, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	data, err := client.Send(context.Background(), []byte(address))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != address {
+		t.Fatalf("got %s != want %s", data, address)
+	}
+}
+
+// go test -v -cover -run=^TestTCPTransport$
+func TestTCPTransport(t *testing.T) {
+	transport := NewTCPTransport()
+
+	listener, err := transport.Listen(context.Background(), "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	address := listener.Addr().String()
+	listener.Close()
+
+	testTransport(t, transport, address)
+}
+
+// go test -v -cover -run=^TestUnixTransport$
+func TestUnixTransport(t *testing.T) {
+	address := filepath.Join(t.TempDir(), "vex.sock")
+	testTransport(t, NewUnixTransport(), address)
+}
+
+// go test -v -cover -run=^TestPipeTransport$
+func TestPipeTransport(t *testing.T) {
+	transport := NewPipeTransport()
+	ctx := context.Background()
+
+	if _, err := transport.Dial(ctx, "pipe"); err != errPipeRefused {
+		t.Fatalf("got %+v != want %+v", err, errPipeRefused)
+	}
+
+	listener, err := transport.Listen(ctx, "pipe")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = transport.Listen(ctx, "pipe"); err != errPipeListening {
+		t.Fatalf("got %+v != want %+v", err, errPipeListening)
+	}
+
+	if listener.Addr().Network() != "pipe" || listener.Addr().String() != "pipe" {
+		t.Fatalf("got %+v is wrong", listener.Addr())
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	if _, err = transport.Dial(timeoutCtx, "pipe"); err != context.DeadlineExceeded {
+		t.Fatalf("got %+v != want %+v", err, context.DeadlineExceeded)
+	}
+
+	if err = listener.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = listener.Accept(); !errors.Is(err, net.ErrClosed) {
+		t.Fatalf("got %+v != want %+v", err, net.ErrClosed)
+	}
+
+	testTransport(t, transport, "pipe")
+}
+
+// go test -v -cover -run=^TestPipeTransportTLS$
+func TestPipeTransportTLS(t *testing.T) {
+	serverConfig, clientConfig := newTestTLSConfigs(t)
+	clientConfig.ServerName = "127.0.0.1"
+
+	transport := NewPipeTransport()
+	address := "pipe"
+
+	svr := NewServer(address, new(testHandler), WithTransport(transport), WithTLSConfig(serverConfig))
+
+	go func() {
+		if err := svr.Serve(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	defer svr.Close()
+
+	time.Sleep(100 * time.Millisecond)
+
+	client, err := NewClient(address, WithTransport(transport), WithTLSConfig(clientConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	data, err := client.Send(context.Background(), []byte(address))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != address {
+		t.Fatalf("got %s != want %s", data, address)
+	}
+}